@@ -0,0 +1,105 @@
+package goimportmaps
+
+import (
+	"sort"
+)
+
+// Cycles returns the import cycles of the graph: its strongly connected components with more than one package.
+// Packages of each cycle are sorted, and cycles are sorted by their first package.
+func (g Graph) Cycles() [][]string {
+	t := &tarjan{
+		graph:   g,
+		index:   make(map[string]int),
+		lowlink: make(map[string]int),
+		onStack: make(map[string]bool),
+	}
+
+	for _, pkg := range g.Packages() {
+		if _, visited := t.index[pkg]; !visited {
+			t.connect(pkg)
+		}
+	}
+
+	sort.Slice(t.components, func(i, j int) bool {
+		return t.components[i][0] < t.components[j][0]
+	})
+	return t.components
+}
+
+// ShortestCycle returns the shortest cycle going through pkg within the given packages,
+// starting and ending with pkg, or nil if there is none. Among cycles of the same length,
+// the one through the first import in sorted order is returned.
+func (g Graph) ShortestCycle(pkg string, within []string) []string {
+	sub := g.within(within)
+
+	toList := append([]string(nil), sub[pkg]...)
+	sort.Strings(toList)
+
+	var shortest []string
+	for _, next := range toList {
+		path := sub.ShortestPath(next, pkg)
+		if next == pkg {
+			path = []string{pkg}
+		}
+		if path != nil && (shortest == nil || len(path)+1 < len(shortest)) {
+			shortest = append([]string{pkg}, path...)
+		}
+	}
+	return shortest
+}
+
+// within returns the subgraph of the imports between the given packages.
+func (g Graph) within(packages []string) Graph {
+	set := make(map[string]bool, len(packages))
+	for _, p := range packages {
+		set[p] = true
+	}
+	return g.Filter(func(from, to string) bool { return set[from] && set[to] })
+}
+
+// tarjan implements Tarjan's strongly connected components algorithm.
+type tarjan struct {
+	graph      Graph
+	counter    int
+	index      map[string]int
+	lowlink    map[string]int
+	stack      []string
+	onStack    map[string]bool
+	components [][]string
+}
+
+func (t *tarjan) connect(pkg string) {
+	t.index[pkg] = t.counter
+	t.lowlink[pkg] = t.counter
+	t.counter++
+	t.stack = append(t.stack, pkg)
+	t.onStack[pkg] = true
+
+	for _, next := range t.graph[pkg] {
+		if _, visited := t.index[next]; !visited {
+			t.connect(next)
+			t.lowlink[pkg] = min(t.lowlink[pkg], t.lowlink[next])
+		} else if t.onStack[next] {
+			t.lowlink[pkg] = min(t.lowlink[pkg], t.index[next])
+		}
+	}
+
+	if t.lowlink[pkg] != t.index[pkg] {
+		return
+	}
+
+	var component []string
+	for {
+		last := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[last] = false
+		component = append(component, last)
+		if last == pkg {
+			break
+		}
+	}
+	if len(component) > 1 {
+		sort.Strings(component)
+		t.components = append(t.components, component)
+	}
+}
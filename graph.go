@@ -1,4 +1,198 @@
 package goimportmaps
 
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
 // Graph maps package -> list of imported packages
 type Graph map[string][]string
+
+// Position is the location of an import spec in a Go source file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Filter returns a graph containing only the edges for which keep returns true.
+func (g Graph) Filter(keep func(from, to string) bool) Graph {
+	filtered := make(Graph)
+	for from, toList := range g {
+		for _, to := range toList {
+			if keep(from, to) {
+				filtered[from] = append(filtered[from], to)
+			}
+		}
+	}
+	return filtered
+}
+
+// Collapse returns the graph with packages folded into the group returned by group,
+// dropping the imports within a group. Packages for which group returns an empty string are kept as is.
+// Imports of each node are sorted.
+func (g Graph) Collapse(group func(pkg string) string) Graph {
+	name := groupName(group)
+
+	seen := make(map[string]map[string]bool)
+	collapsed := make(Graph)
+	for from, toList := range g {
+		groupFrom := name(from)
+		for _, to := range toList {
+			groupTo := name(to)
+			if groupFrom == groupTo || seen[groupFrom][groupTo] {
+				continue
+			}
+			if seen[groupFrom] == nil {
+				seen[groupFrom] = make(map[string]bool)
+			}
+			seen[groupFrom][groupTo] = true
+			collapsed[groupFrom] = append(collapsed[groupFrom], groupTo)
+		}
+	}
+	for from := range collapsed {
+		sort.Strings(collapsed[from])
+	}
+	return collapsed
+}
+
+// groupName returns the name of the node a package is folded into by group: its group, or itself if none.
+func groupName(group func(pkg string) string) func(pkg string) string {
+	return func(pkg string) string {
+		if n := group(pkg); n != "" {
+			return n
+		}
+		return pkg
+	}
+}
+
+// Packages returns every package of the graph, importing or imported, sorted.
+func (g Graph) Packages() []string {
+	set := make(map[string]bool)
+	for from, toList := range g {
+		set[from] = true
+		for _, to := range toList {
+			set[to] = true
+		}
+	}
+
+	packages := make([]string, 0, len(set))
+	for pkg := range set {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// Edge holds the details of an import from one package to another
+type Edge struct {
+	Positions []Position
+	// Test is true if the import only appears in test files.
+	Test bool
+	// Builds lists the build configurations the import appears in,
+	// or is empty if packages were loaded under the host build context only.
+	Builds []string
+	// Suppressions lists the suppression comments applying to the import specs of the edge.
+	Suppressions []Suppression
+	// Weight is the number of package imports a collapsed edge stands for (see Edges.Collapse),
+	// or 0 for an import between two packages.
+	Weight int
+}
+
+// Suppression is a `//goimportmaps:ignore <rule-id> [reason]` comment, attached to an import spec
+// or at the top of a file, which suppresses the violations of a rule caused by the import spec(s).
+type Suppression struct {
+	RuleID string
+	Reason string
+	// Position is the position of the import spec suppressed.
+	Position Position
+	// Comment is the position of the comment, shared by every import spec of the file for file-level comments.
+	Comment Position
+}
+
+// Edges maps package -> imported package -> edge details
+type Edges map[string]map[string]*Edge
+
+// Get returns the edge between the given packages, or nil if there is none.
+func (e Edges) Get(from, to string) *Edge {
+	return e[from][to]
+}
+
+// Add returns the edge between the given packages, creating it if needed.
+func (e Edges) Add(from, to string) *Edge {
+	if e[from] == nil {
+		e[from] = make(map[string]*Edge)
+	}
+	if e[from][to] == nil {
+		e[from][to] = &Edge{}
+	}
+	return e[from][to]
+}
+
+// Graph returns the import graph formed by the edges.
+func (e Edges) Graph() Graph {
+	graph := make(Graph)
+	for from, imports := range e {
+		for to := range imports {
+			graph[from] = append(graph[from], to)
+		}
+		sort.Strings(graph[from])
+	}
+	return graph
+}
+
+// Collapse returns the edges of the graph collapsed with the given groups (see Graph.Collapse).
+// Each edge merges the package imports it stands for: their positions, builds and suppressions are combined,
+// it is a test import only if they all are, and its weight is their number.
+func (e Edges) Collapse(graph Graph, group func(pkg string) string) Edges {
+	name := groupName(group)
+
+	froms := make([]string, 0, len(graph))
+	for from := range graph {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	collapsed := make(Edges)
+	for _, from := range froms {
+		toList := append([]string(nil), graph[from]...)
+		sort.Strings(toList)
+		for _, to := range toList {
+			groupFrom, groupTo := name(from), name(to)
+			if groupFrom == groupTo {
+				continue
+			}
+
+			edge := e.Get(from, to)
+			if edge == nil {
+				edge = &Edge{}
+			}
+			merged := collapsed.Get(groupFrom, groupTo)
+			if merged == nil {
+				merged = collapsed.Add(groupFrom, groupTo)
+				merged.Test = edge.Test
+			}
+			merged.Positions = append(merged.Positions, edge.Positions...)
+			merged.Test = merged.Test && edge.Test
+			for _, build := range edge.Builds {
+				if !slices.Contains(merged.Builds, build) {
+					merged.Builds = append(merged.Builds, build)
+				}
+			}
+			merged.Suppressions = append(merged.Suppressions, edge.Suppressions...)
+			merged.Weight++
+		}
+	}
+	return collapsed
+}
+
+// IsTest reports whether the edge between the given packages only appears in test files.
+func (e Edges) IsTest(from, to string) bool {
+	edge := e.Get(from, to)
+	return edge != nil && edge.Test
+}
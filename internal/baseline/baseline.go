@@ -0,0 +1,126 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mickamy/goimportmaps/internal/config"
+)
+
+// Path is the default path of the baseline file.
+const Path = ".goimportmaps-baseline.json"
+
+// Version is bumped whenever a backward incompatible change is made to the baseline file.
+const Version = 1
+
+// Baseline lists the violations accepted when it was written, so that only new ones fail the check.
+type Baseline struct {
+	Version    int     `json:"version"`
+	Violations []Entry `json:"violations"`
+}
+
+// Entry identifies an accepted violation. The message is only kept for readers of the file.
+type Entry struct {
+	RuleID  string   `json:"rule_id"`
+	Source  string   `json:"source,omitempty"`
+	Import  string   `json:"import,omitempty"`
+	Cycle   []string `json:"cycle,omitempty"`
+	Message string   `json:"message"`
+}
+
+func (e Entry) key() string {
+	return strings.Join([]string{e.RuleID, e.Source, e.Import, strings.Join(e.Cycle, " → ")}, "\x00")
+}
+
+func entryOf(v config.Violation) Entry {
+	return Entry{
+		RuleID:  v.RuleID,
+		Source:  v.Source,
+		Import:  v.Import,
+		Cycle:   v.Cycle,
+		Message: v.Message,
+	}
+}
+
+// New returns the baseline accepting the given violations.
+func New(violations []config.Violation) *Baseline {
+	b := &Baseline{Version: Version, Violations: []Entry{}}
+
+	seen := make(map[string]bool)
+	for _, v := range violations {
+		entry := entryOf(v)
+		if seen[entry.key()] {
+			continue
+		}
+		seen[entry.key()] = true
+		b.Violations = append(b.Violations, entry)
+	}
+
+	sort.Slice(b.Violations, func(i, j int) bool { return b.Violations[i].key() < b.Violations[j].key() })
+	return b
+}
+
+// Load reads the baseline file at the given path. A missing file is an empty baseline.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Baseline{Version: Version}, nil
+		}
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid baseline format: %w", err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d, write it again", b.Version)
+	}
+
+	return &b, nil
+}
+
+// Write writes the baseline file at the given path.
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+	return nil
+}
+
+// Subtract returns the violations not accepted by the baseline,
+// and the entries of the baseline that no longer occur, so that they can be pruned.
+// If analyzed is not nil, entries it rejects, e.g. of packages that were not loaded, are not reported as fixed.
+func (b *Baseline) Subtract(violations []config.Violation, analyzed func(Entry) bool) ([]config.Violation, []Entry) {
+	accepted := make(map[string]bool, len(b.Violations))
+	for _, entry := range b.Violations {
+		accepted[entry.key()] = true
+	}
+
+	var remaining []config.Violation
+	occurring := make(map[string]bool)
+	for _, v := range violations {
+		key := entryOf(v).key()
+		occurring[key] = true
+		if !accepted[key] {
+			remaining = append(remaining, v)
+		}
+	}
+
+	var fixed []Entry
+	for _, entry := range b.Violations {
+		if !occurring[entry.key()] && (analyzed == nil || analyzed(entry)) {
+			fixed = append(fixed, entry)
+		}
+	}
+
+	return remaining, fixed
+}
@@ -0,0 +1,74 @@
+package baseline
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mickamy/goimportmaps/internal/baseline"
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
+	"github.com/mickamy/goimportmaps/internal/config"
+)
+
+var (
+	mode      = "forbidden"
+	output    = baseline.Path
+	loadFlags loader.Flags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the baseline of accepted violations",
+	Long: `The baseline lists violations that are accepted for now, so that the check command only fails on new ones.
+
+This makes it possible to adopt stricter rules on an existing codebase and fix its violations incrementally.`,
+}
+
+var writeCmd = &cobra.Command{
+	Use:   "write [patterns...]",
+	Short: "Record the current violations to the baseline file",
+	Long: `Record the current violations to the baseline file (.goimportmaps-baseline.json by default).
+
+The check command then only fails on violations that are not in the baseline,
+and reports the baseline entries that have been fixed. Run it again to prune them.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		mode, err := config.NewMode(mode)
+		if err != nil {
+			return err
+		}
+
+		Run(cfg, mode, args)
+		return nil
+	},
+}
+
+func init() {
+	writeCmd.Flags().StringVarP(&mode, "mode", "m", "forbidden", "check mode (forbidden or allowed)")
+	writeCmd.Flags().StringVarP(&output, "output", "o", baseline.Path, "path of the baseline file")
+	loadFlags.Register(writeCmd)
+
+	Cmd.AddCommand(writeCmd)
+}
+
+func Run(cfg *config.Config, mode config.Mode, patterns []string) {
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	b := baseline.New(config.Active(cfg.Validate(loaded.Packages, loaded.Deps, loaded.PackageEdges, mode, loaded.ModulePath, loaded.Modules)))
+	if err := b.Write(output); err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("📝 %d violation(s) written to %s\n", len(b.Violations), output)
+}
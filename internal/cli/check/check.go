@@ -3,67 +3,131 @@ package check
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/spf13/cobra"
 
+	"github.com/mickamy/goimportmaps/internal/baseline"
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
 	"github.com/mickamy/goimportmaps/internal/config"
-	"github.com/mickamy/goimportmaps/internal/module"
-	"github.com/mickamy/goimportmaps/internal/parser"
+	"github.com/mickamy/goimportmaps/internal/prints"
+)
+
+// Exit codes of the check command. Rule and coupling violations combine, e.g. 3 when both are found.
+const (
+	ExitRuleViolations     = 1
+	ExitCouplingViolations = 2
+	ExitError              = 4
 )
 
 var (
-	mode = "forbidden"
+	mode          = "forbidden"
+	baselinePath  = baseline.Path
+	checkCoupling = false
+	checkSDP      = false
+	loadFlags     loader.Flags
 )
 
 var Cmd = &cobra.Command{
-	Use:   "check [pattern]",
+	Use:   "check [patterns...]",
 	Short: "Check for forbidden imports defined in .goimportmaps.yaml",
 	Long: `Check your Go package dependencies against forbidden import rules.
 
 Rules must be defined in a .goimportmaps.yaml file at the project root.
-If any violations are found, they will be printed to stderr and the program will exit with code 1.`,
-	Args: cobra.ExactArgs(1),
+If any violations are found, they will be printed to stderr and the program will exit with code 1,
+unless they all come from rules of warning or info severity.
+
+With --metrics (or metrics.check in the config), the coupling metrics of every package are checked as well:
+exceeding a max_* threshold makes the program exit with code 2 (3 along with rule violations),
+while exceeding a warn_* threshold is only reported. With --sdp (or metrics.check_stable_dependencies),
+imports of packages less stable than their importer (Stable Dependencies Principle) exit with code 2 as well. Errors, e.g. failing to load packages, exit with code 4.
+
+Violations recorded in the baseline file (see the baseline command) are accepted, and baseline entries
+that no longer occur are reported so that the baseline can be pruned.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
 		if err != nil {
 			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			os.Exit(ExitError)
 		}
 
 		mode, err := config.NewMode(mode)
 		if err != nil {
-			return err
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(ExitError)
 		}
 
-		Run(cfg, mode, args[0])
+		Run(cfg, mode, args)
 		return nil
 	},
 }
 
 func init() {
 	Cmd.Flags().StringVarP(&mode, "mode", "m", "forbidden", "check mode (forbidden or allowed)")
+	Cmd.Flags().BoolVar(&checkCoupling, "metrics", false, "check coupling metrics against thresholds (overrides config setting)")
+	Cmd.Flags().BoolVar(&checkSDP, "sdp", false, "check that packages only import more stable packages (overrides config setting)")
+	Cmd.Flags().StringVar(&baselinePath, "baseline", baseline.Path, "path of the baseline file of accepted violations (ignored if missing)")
+	loadFlags.Register(Cmd)
 }
 
-func Run(cfg *config.Config, mode config.Mode, pattern string) {
-	data, err := parser.ExtractImports(pattern)
+func Run(cfg *config.Config, mode config.Mode, patterns []string) {
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
-		os.Exit(1)
+		os.Exit(ExitError)
 	}
+	data, edges, modulePath, modules := loaded.Graph, loaded.Edges, loaded.ModulePath, loaded.Modules
 
-	modulePath, err := module.Path()
+	b, err := baseline.Load(baselinePath)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
-		os.Exit(1)
+		os.Exit(ExitError)
+	}
+
+	// rules match packages, so they are validated against the graph before it is collapsed
+	all := cfg.Validate(loaded.Packages, loaded.Deps, loaded.PackageEdges, mode, modulePath, modules)
+	// entries of packages left out by the patterns are not fixed, their imports are unknown
+	violations, fixed := b.Subtract(config.Active(all), func(entry baseline.Entry) bool {
+		return cfg.Analyzed(loaded.Packages, entry.RuleID, entry.Source, entry.Cycle, modulePath)
+	})
+	if checkSDP {
+		cfg.Metrics.CheckStableDependencies = true
 	}
+	// stable dependencies are validated even if not checked, so that their suppressions count as used
+	analysis := loaded.Coupling(cfg)
+	sdp := cfg.ValidateStableDependencies(data, edges, analysis, modulePath)
+	prints.Warnings(os.Stderr, cfg.Warnings(loaded.Packages, loaded.PackageEdges, mode, slices.Concat(all, sdp)))
+	prints.Fixed(os.Stderr, fixed)
+	prints.Violations(os.Stderr, violations)
 
-	violations := cfg.Validate(data, mode, modulePath)
-	if len(violations) > 0 {
-		_, _ = fmt.Fprintf(os.Stderr, "\n🚨 %d violation(s) found\n\n", len(violations))
+	code := 0
+	if hasErrors(violations) {
+		code |= ExitRuleViolations
+	}
+
+	if checkCoupling || cfg.Metrics.Check || cfg.Metrics.CheckStableDependencies {
+		var couplingViolations []config.Violation
+		if checkCoupling || cfg.Metrics.Check {
+			couplingViolations = append(couplingViolations, cfg.ValidateCoupling(analysis, modulePath)...)
+		}
+		if cfg.Metrics.CheckStableDependencies {
+			couplingViolations = append(couplingViolations, config.Active(sdp)...)
+		}
+		prints.CouplingViolations(os.Stderr, couplingViolations)
+		if hasErrors(couplingViolations) {
+			code |= ExitCouplingViolations
+		}
+	}
+
+	os.Exit(code)
+}
 
-		for _, violation := range violations {
-			_, _ = fmt.Fprintln(os.Stderr, "🚨 Violation:", violation.Message)
+func hasErrors(violations []config.Violation) bool {
+	for _, v := range violations {
+		if v.Severity == config.SeverityError {
+			return true
 		}
-		os.Exit(1)
 	}
+	return false
 }
@@ -0,0 +1,81 @@
+package cycles
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+var (
+	loadFlags loader.Flags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "cycles [patterns...]",
+	Short: "Detect import cycles between packages and components",
+	Long: `Detect import cycles in your Go package dependencies.
+
+Cycles between packages, or between groups of packages with --collapse, are always reported. Imports only found in
+test files are left out of them, as an external test package (p_test) may import packages importing the package under test.
+Cycles between components, i.e. groups of packages, are reported for every acyclic rule defined in .goimportmaps.yaml.
+If any cycles are found, the program will exit with code 1.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		Run(cfg, args)
+		return nil
+	},
+}
+
+func init() {
+	loadFlags.Register(Cmd)
+}
+
+func Run(cfg *config.Config, patterns []string) {
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	data, edges, modulePath := loaded.Graph, loaded.Edges, loaded.ModulePath
+
+	// imports of external test packages are attributed to the package under test, which they may import indirectly
+	production := data.Filter(func(from, to string) bool { return !edges.IsTest(from, to) })
+
+	var lines []string
+	for _, scc := range production.Cycles() {
+		cycle := production.ShortestCycle(scc[0], scc)
+		for i, pkg := range cycle {
+			cycle[i] = module.Shorten(pkg, modulePath)
+		}
+		lines = append(lines, strings.Join(cycle, " → "))
+	}
+	for _, rule := range cfg.Acyclic {
+		// components match packages, so they are looked for in the graph before it is collapsed
+		for _, cycle := range rule.Cycles(loaded.Packages, modulePath) {
+			lines = append(lines, fmt.Sprintf("%s (%s)", strings.Join(cycle, " → "), rule.String()))
+		}
+	}
+
+	if len(lines) == 0 {
+		fmt.Println("✅ no cycles found")
+		return
+	}
+
+	fmt.Printf("🔁 %d cycle(s) found\n\n", len(lines))
+	for _, line := range lines {
+		fmt.Println("🔁 Cycle:", line)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/diff"
+	"github.com/mickamy/goimportmaps/internal/git"
+	"github.com/mickamy/goimportmaps/internal/prints"
+)
+
+var (
+	mode      = "forbidden"
+	loadFlags loader.Flags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "diff <base-ref> [<head-ref>] [-- patterns...]",
+	Short: "Compare the import graph of two git revisions",
+	Long: `Compare the import graph of two git revisions, e.g. the target and the head of a pull request,
+and report the packages and imports added or removed, the new and fixed violations, and the coupling metrics that changed.
+
+Revisions are checked out in temporary git worktrees, and analyzed with their own .goimportmaps.yaml.
+Without <head-ref>, the base revision is compared to the working tree, uncommitted changes included.
+Packages matching the patterns given after -- are analyzed, ./... by default.
+If the head revision has new violations, the program will exit with code 1,
+unless they all come from rules of warning or info severity.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		revisions := args
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			revisions = args[:dash]
+		}
+		if len(revisions) < 1 || len(revisions) > 2 {
+			return fmt.Errorf("accepts 1 or 2 revisions, received %d", len(revisions))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, err := config.NewMode(mode)
+		if err != nil {
+			return err
+		}
+
+		revisions, patterns := args, []string{"./..."}
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			revisions = args[:dash]
+			if len(args[dash:]) > 0 {
+				patterns = args[dash:]
+			}
+		}
+		var head string
+		if len(revisions) > 1 {
+			head = revisions[1]
+		}
+
+		Run(mode, revisions[0], head, patterns)
+		return nil
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&mode, "mode", "m", "forbidden", "check mode (forbidden or allowed)")
+	loadFlags.Register(Cmd)
+}
+
+func Run(mode config.Mode, base, head string, patterns []string) {
+	baseSnapshot, _, err := snapshotAt(base, mode, patterns)
+	if err != nil {
+		fmt.Printf("error: %s: %v\n", base, err)
+		os.Exit(1)
+	}
+
+	var headSnapshot *diff.Snapshot
+	var modulePath string
+	if head == "" {
+		headSnapshot, modulePath, err = snapshot(mode, patterns)
+	} else {
+		headSnapshot, modulePath, err = snapshotAt(head, mode, patterns)
+	}
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := diff.Compare(*baseSnapshot, *headSnapshot)
+	prints.Diff(os.Stdout, result, modulePath)
+
+	for _, v := range result.NewViolations {
+		if v.Severity == config.SeverityError {
+			os.Exit(1)
+		}
+	}
+}
+
+// snapshotAt analyzes the given revision, checked out in a temporary worktree.
+// The directory of the worktree corresponding to the current one is analyzed, so that patterns resolve alike.
+func snapshotAt(ref string, mode config.Mode, patterns []string) (*diff.Snapshot, string, error) {
+	prefix, err := git.Prefix()
+	if err != nil {
+		return nil, "", err
+	}
+	root, remove, err := git.Worktree(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err := remove(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: failed to remove worktree %s: %v\n", root, err)
+		}
+	}()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, "", err
+	}
+	// packages are loaded from the current directory
+	if err := os.Chdir(filepath.Join(root, filepath.FromSlash(prefix))); err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	return snapshot(mode, patterns)
+}
+
+// snapshot analyzes the packages of the current directory with its config.
+func snapshot(mode config.Mode, patterns []string) (*diff.Snapshot, string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", err
+	}
+
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
+	if err != nil {
+		return nil, "", err
+	}
+	analysis, err := loaded.Metrics(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &diff.Snapshot{
+		Graph:      loaded.Graph,
+		Violations: cfg.Validate(loaded.Packages, loaded.Deps, loaded.PackageEdges, mode, loaded.ModulePath, loaded.Modules),
+		Metrics:    analysis,
+	}, loaded.ModulePath, nil
+}
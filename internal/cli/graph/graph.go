@@ -6,64 +6,104 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
 	"github.com/mickamy/goimportmaps/internal/config"
-	"github.com/mickamy/goimportmaps/internal/module"
-	"github.com/mickamy/goimportmaps/internal/parser"
 	"github.com/mickamy/goimportmaps/internal/prints"
 )
 
 var (
-	format = "text"
+	format    = "text"
+	focus     []string
+	depth     = 1
+	direction = "both"
+	loadFlags loader.Flags
 )
 
 var Cmd = &cobra.Command{
-	Use:   "graph [pattern]",
+	Use:   "graph [patterns...]",
 	Short: "Print package dependency graph",
 	Long: `The graph command analyzes your Go packages and prints their internal import relationships.
 
 Use it to inspect how packages depend on each other, or to generate raw dependency data before formatting it as a graph.
 
-This is useful for understanding the structure of your project and preparing for visualization (e.g., Mermaid output).`,
-	Args: cobra.ExactArgs(1),
+This is useful for understanding the structure of your project and preparing for visualization (e.g., Mermaid output).
+
+With --focus, only the neighborhood of the matching packages is printed: the packages at most --depth imports away,
+following their imports (--direction out), their importers (in) or both (default).`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
 		format, err := prints.NewFormat(format)
 		if err != nil {
 			return err
 		}
 
-		Run(args[0], format)
+		direction, err := goimportmaps.NewDirection(direction)
+		if err != nil {
+			return err
+		}
+
+		Run(cfg, args, format, direction)
 		return nil
 	},
 }
 
 func init() {
-	Cmd.Flags().StringVarP(&format, "format", "f", "text", "output format (text, mermaid, graphviz or html)")
+	Cmd.Flags().StringVarP(&format, "format", "f", "text", "output format (text, mermaid, graphviz, html, json or sarif)")
+	Cmd.Flags().StringArrayVar(&focus, "focus", nil, "only print the neighborhood of the packages matching the ./-relative Go package pattern or package path (repeatable)")
+	Cmd.Flags().IntVar(&depth, "depth", 1, "maximum number of imports away from the focused packages (0 for no limit)")
+	Cmd.Flags().StringVar(&direction, "direction", "both", "imports to follow from the focused packages: in (importers), out (imports) or both")
+	loadFlags.Register(Cmd)
 }
 
-func Run(pattern string, format prints.Format) {
-	data, err := parser.ExtractImports(pattern)
+func Run(cfg *config.Config, patterns []string, format prints.Format, direction goimportmaps.Direction) {
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
 		os.Exit(1)
 	}
+	data, edges, modulePath, modules := loaded.Graph, loaded.Edges, loaded.ModulePath, loaded.Modules
 
-	modulePath, err := module.Path()
-	if err != nil {
-		fmt.Printf("error: %v\n", err)
-		os.Exit(1)
+	if len(focus) > 0 {
+		var focused []string
+		for _, arg := range focus {
+			packages, err := loaded.Resolve(arg)
+			if err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+			focused = append(focused, packages...)
+		}
+		data = data.Neighborhood(focused, depth, direction)
 	}
 
 	switch format {
 	case prints.FormatGraphviz:
-		prints.Graphviz(os.Stdout, data, modulePath)
+		prints.Graphviz(os.Stdout, data, edges, modulePath, modules, []config.Violation{})
 	case prints.FormatHTML:
-		if err := prints.HTML(os.Stdout, data, modulePath, []config.Violation{}); err != nil {
+		if err := prints.HTML(os.Stdout, data, edges, modulePath, modules, []config.Violation{}); err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+	case prints.FormatJSON:
+		if err := prints.JSON(os.Stdout, data, edges, modulePath, modules, []config.Violation{}, nil); err != nil {
 			fmt.Printf("error: %v\n", err)
 			os.Exit(1)
 		}
 	case prints.FormatMermaid:
-		prints.Mermaid(os.Stdout, data, modulePath, []config.Violation{})
+		prints.Mermaid(os.Stdout, data, edges, modulePath, modules, []config.Violation{})
+	case prints.FormatSARIF:
+		if err := prints.SARIF(os.Stdout, []config.Violation{}); err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
 	case prints.FormatText:
-		prints.Text(os.Stdout, data, modulePath)
+		prints.Text(os.Stdout, data, edges, modulePath)
 	}
 }
@@ -0,0 +1,121 @@
+package importers
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+var (
+	depth     = 0
+	mainOnly  = false
+	loadFlags loader.Flags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "importers <package> [patterns...]",
+	Short: "List the packages importing a package, directly or transitively",
+	Long: `List the packages importing a package, directly or transitively, to assess the impact of changing it.
+
+<package> is a package path, either full (e.g. database/sql) or relative to the module path (e.g. internal/repository),
+or a ./-relative Go package pattern (e.g. ./internal/repository/...). Importers are searched for among the packages
+matching the patterns, ./... by default. Each importer is printed with its depth, i.e. the number of imports between
+it and the package, and the shortest import path leading to the package.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		patterns := args[1:]
+		if len(patterns) == 0 {
+			patterns = []string{"./..."}
+		}
+
+		Run(cfg, args[0], patterns)
+		return nil
+	},
+}
+
+func init() {
+	Cmd.Flags().IntVar(&depth, "depth", 0, "maximum depth of the importers to list (0 for no limit, 1 for direct importers only)")
+	Cmd.Flags().BoolVar(&mainOnly, "main", false, "only list main packages, i.e. the binaries affected")
+	loadFlags.Register(Cmd)
+}
+
+type importer struct {
+	pkg  string
+	path []string
+}
+
+func Run(cfg *config.Config, target string, patterns []string) {
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	data, edges, modulePath := loaded.Graph, loaded.Edges, loaded.ModulePath
+
+	// packages without any import or importer are not part of the graph
+	targets, err := loaded.Resolve(target)
+	if err != nil {
+		fmt.Printf("%s is not imported by any package\n", target)
+		return
+	}
+
+	var mains []string
+	if mainOnly {
+		if mains, err = loaded.MainPackages(); err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	reversed := data.Reverse()
+	for i, pkg := range targets {
+		var importers []importer
+		for _, imprt := range reversed.Reachable(pkg) {
+			if mainOnly && !slices.Contains(mains, imprt) {
+				continue
+			}
+			path := data.ShortestPath(imprt, pkg)
+			if depth > 0 && len(path)-1 > depth {
+				continue
+			}
+			importers = append(importers, importer{pkg: imprt, path: path})
+		}
+		sort.SliceStable(importers, func(i, j int) bool { return len(importers[i].path) < len(importers[j].path) })
+
+		if i > 0 {
+			fmt.Println()
+		}
+		short := module.Shorten(pkg, modulePath)
+		if len(importers) == 0 {
+			fmt.Printf("%s is not imported by any package\n", short)
+			continue
+		}
+
+		fmt.Printf("%s is imported by %d package(s):\n\n", short, len(importers))
+		for _, imp := range importers {
+			path := make([]string, len(imp.path))
+			for i, p := range imp.path {
+				path[i] = module.Shorten(p, modulePath)
+			}
+			line := fmt.Sprintf("  %d  %s", len(imp.path)-1, strings.Join(path, " → "))
+			if edge := edges.Get(imp.path[0], imp.path[1]); edge != nil && len(edge.Positions) > 0 {
+				line += fmt.Sprintf(" (%s)", edge.Positions[0])
+			}
+			fmt.Println(line)
+		}
+	}
+}
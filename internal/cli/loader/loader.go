@@ -0,0 +1,280 @@
+package loader
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/metrics"
+	"github.com/mickamy/goimportmaps/internal/module"
+	"github.com/mickamy/goimportmaps/internal/parser"
+	"github.com/mickamy/goimportmaps/internal/pattern"
+)
+
+// Flags holds the command line flags shared by the commands that load packages.
+type Flags struct {
+	Tests   bool
+	Builds  []string
+	Exclude []string
+	// Collapse folds packages into groups (see config.Config.Collapse).
+	Collapse string
+
+	// Deps is not a flag, but set by the commands working on the transitive graph (see parser.Options).
+	Deps bool
+}
+
+// Register registers the flags on the given command.
+func (f *Flags) Register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.Tests, "tests", false, "include test files and external test packages")
+	cmd.Flags().StringArrayVar(&f.Builds, "build", nil, "build configuration to analyze, as [GOOS/GOARCH][:tags] (repeatable)")
+	cmd.Flags().StringArrayVar(&f.Exclude, "exclude", nil, "packages to exclude, in the pattern syntax of the config (regex or glob), or as ./-relative Go package pattern (repeatable)")
+	cmd.Flags().StringVar(&f.Collapse, "collapse", "", "fold packages into groups: depth:N (directories N levels below the module root) or components (from the config)")
+}
+
+// Result is the import graph of the loaded packages.
+type Result struct {
+	Graph goimportmaps.Graph
+	Edges goimportmaps.Edges
+	// ModulePath is the path package paths are shortened against (see module.Path).
+	ModulePath string
+	// Modules lists the paths of the main modules (more than one in a go.work workspace).
+	Modules []string
+	// Packages and PackageEdges are the graph before it is collapsed (see Flags.Collapse), the same as Graph
+	// and Edges otherwise. Rules are validated against them, as their patterns match packages rather than groups.
+	Packages     goimportmaps.Graph
+	PackageEdges goimportmaps.Edges
+	// Deps is the graph of the production imports of the packages and their dependencies, walked by transitive rules.
+	// It is only loaded if the config has some (see config.Config.HasTransitive), and nil otherwise.
+	Deps goimportmaps.Graph
+
+	patterns []string
+	options  parser.Options
+	// members lists the packages of each group of a collapsed graph.
+	members map[string][]string
+	group   func(pkg string) string
+}
+
+// Load loads the packages matching the given patterns and extracts their import graph.
+// Build configurations given as flags take precedence over the config, while exclusions are combined.
+// With the collapse flag, the graph is collapsed before being returned, so that groups stand for packages.
+func Load(cfg *config.Config, flags Flags, patterns []string) (*Result, error) {
+	modules, err := module.Modules()
+	if err != nil {
+		return nil, err
+	}
+	modulePath := module.Root(module.Paths(modules))
+
+	patterns, err = module.ExpandPatterns(patterns, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := flags.Builds
+	if len(specs) == 0 {
+		specs = cfg.Builds
+	}
+	builds, err := parser.ParseBuilds(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := cfg.Exclusions(flags.Exclude, modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	options := parser.Options{Tests: flags.Tests, Builds: builds, Deps: flags.Deps}
+	graph, edges, err := parser.ExtractImports(patterns, options)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := func(from, to string) bool { return matchAny(exclude, from) || matchAny(exclude, to) }
+	if len(exclude) > 0 {
+		graph = graph.Filter(func(from, to string) bool { return !excluded(from, to) })
+	}
+
+	var deps goimportmaps.Graph
+	if cfg.HasTransitive() {
+		depsGraph, depsEdges := graph, edges
+		if !flags.Deps {
+			// the imports of dependencies are only walked, other rules and printers sticking to the loaded packages
+			depsOptions := options
+			depsOptions.Deps = true
+			if depsGraph, depsEdges, err = parser.ExtractImports(patterns, depsOptions); err != nil {
+				return nil, err
+			}
+		}
+		deps = depsGraph.Filter(func(from, to string) bool { return !excluded(from, to) && !depsEdges.IsTest(from, to) })
+	}
+
+	result := &Result{
+		Graph:        graph,
+		Edges:        edges,
+		ModulePath:   modulePath,
+		Modules:      module.Paths(modules),
+		Packages:     graph,
+		PackageEdges: edges,
+		Deps:         deps,
+		patterns:     patterns,
+		options:      options,
+	}
+
+	if flags.Collapse != "" {
+		group, err := cfg.Collapse(flags.Collapse, result.Modules)
+		if err != nil {
+			return nil, err
+		}
+		result.members = make(map[string][]string)
+		for _, pkg := range graph.Packages() {
+			if g := group(pkg); g != "" {
+				result.members[g] = append(result.members[g], pkg)
+			}
+		}
+		result.Graph = graph.Collapse(group)
+		result.Edges = edges.Collapse(graph, group)
+		result.group = group
+	}
+
+	return result, nil
+}
+
+// Coupling calculates the coupling metrics of the graph in the scope set by the config.
+// A group of a collapsed graph is in scope if any of its packages is.
+func (r *Result) Coupling(cfg *config.Config) *metrics.CouplingAnalysis {
+	include := cfg.Metrics.Include(r.Modules)
+	if include != nil && r.members != nil {
+		includePackage := include
+		include = func(node string) bool {
+			members, ok := r.members[node]
+			if !ok {
+				return includePackage(node)
+			}
+			return slices.ContainsFunc(members, includePackage)
+		}
+	}
+	return metrics.CalculateCoupling(r.Graph, include)
+}
+
+// Metrics calculates the coupling metrics of the graph in the scope set by the config,
+// along with the abstractness of the loaded packages, which requires parsing their type declarations.
+func (r *Result) Metrics(cfg *config.Config) (*metrics.CouplingAnalysis, error) {
+	analysis := r.Coupling(cfg)
+
+	types, err := parser.CountTypes(r.patterns, r.options)
+	if err != nil {
+		return nil, err
+	}
+	if r.group != nil {
+		grouped := make(map[string]metrics.TypeCounts)
+		for pkg, counts := range types {
+			if g := r.group(pkg); g != "" {
+				pkg = g
+			}
+			total := grouped[pkg]
+			total.Abstract += counts.Abstract
+			total.Total += counts.Total
+			grouped[pkg] = total
+		}
+		types = grouped
+	}
+	analysis.CalculateAbstractness(types)
+
+	return analysis, nil
+}
+
+// Grouped returns the violations with their packages replaced by their group if the graph is collapsed,
+// so that printers of the collapsed graph can tell the edges they come from.
+func (r *Result) Grouped(violations []config.Violation) []config.Violation {
+	if r.group == nil {
+		return violations
+	}
+
+	name := func(pkg string) string {
+		if g := r.group(pkg); g != "" {
+			return g
+		}
+		return pkg
+	}
+	grouped := make([]config.Violation, len(violations))
+	for i, v := range violations {
+		if v.Source != "" {
+			v.Source, v.Import = name(v.Source), name(v.Import)
+		}
+		if v.Chain != nil {
+			chain := make([]string, len(v.Chain))
+			for j, pkg := range v.Chain {
+				chain[j] = name(pkg)
+			}
+			v.Chain = chain
+		}
+		grouped[i] = v
+	}
+	return grouped
+}
+
+// MainPackages returns the main packages (commands) among the loaded ones, or the groups containing them
+// if the graph is collapsed, sorted.
+func (r *Result) MainPackages() ([]string, error) {
+	mains, err := parser.MainPackages(r.patterns, r.options)
+	if err != nil {
+		return nil, err
+	}
+	if r.group == nil {
+		return mains, nil
+	}
+
+	var groups []string
+	for _, pkg := range mains {
+		if g := r.group(pkg); g != "" {
+			pkg = g
+		}
+		if !slices.Contains(groups, pkg) {
+			groups = append(groups, pkg)
+		}
+	}
+	slices.Sort(groups)
+	return groups, nil
+}
+
+func matchAny(regexps []*regexp.Regexp, s string) bool {
+	for _, re := range regexps {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the packages of the graph a command line argument refers to:
+// a Go package pattern relative to the module root (e.g. `./internal/...`),
+// or a package path, either full or relative to the module path.
+func (r *Result) Resolve(arg string) ([]string, error) {
+	var match func(pkg string) bool
+	if pattern.IsGoPattern(arg) {
+		re, err := pattern.Compile(arg, r.ModulePath)
+		if err != nil {
+			return nil, err
+		}
+		match = re.MatchString
+	} else {
+		match = func(pkg string) bool {
+			return pkg == arg || module.Shorten(pkg, r.ModulePath) == arg
+		}
+	}
+
+	var packages []string
+	for _, pkg := range r.Graph.Packages() {
+		if match(pkg) {
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no package matches %s", arg)
+	}
+	return packages, nil
+}
@@ -3,16 +3,21 @@ package cli
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/spf13/cobra"
 
+	"github.com/mickamy/goimportmaps/internal/cli/baseline"
 	"github.com/mickamy/goimportmaps/internal/cli/check"
+	"github.com/mickamy/goimportmaps/internal/cli/cycles"
+	"github.com/mickamy/goimportmaps/internal/cli/diff"
 	"github.com/mickamy/goimportmaps/internal/cli/graph"
+	"github.com/mickamy/goimportmaps/internal/cli/importers"
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
 	"github.com/mickamy/goimportmaps/internal/cli/version"
+	"github.com/mickamy/goimportmaps/internal/cli/why"
 	"github.com/mickamy/goimportmaps/internal/config"
 	"github.com/mickamy/goimportmaps/internal/metrics"
-	"github.com/mickamy/goimportmaps/internal/module"
-	"github.com/mickamy/goimportmaps/internal/parser"
 	"github.com/mickamy/goimportmaps/internal/prints"
 )
 
@@ -20,6 +25,7 @@ var (
 	format      = "text"
 	mode        = "forbidden"
 	showMetrics = false
+	loadFlags   loader.Flags
 )
 
 var cmd = &cobra.Command{
@@ -27,7 +33,7 @@ var cmd = &cobra.Command{
 	Short: "Visualize and validate Go package dependencies",
 	Long: `goimportmaps is a CLI tool that helps you understand and enforce 
 the architecture of your Go projects by analyzing internal package imports.`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
 		if err != nil {
@@ -45,86 +51,103 @@ the architecture of your Go projects by analyzing internal package imports.`,
 			return err
 		}
 
-		Run(cfg, mode, format, args[0])
+		Run(cfg, mode, format, args)
 
 		return nil
 	},
 }
 
 func init() {
+	cmd.AddCommand(baseline.Cmd)
 	cmd.AddCommand(check.Cmd)
+	cmd.AddCommand(cycles.Cmd)
+	cmd.AddCommand(diff.Cmd)
 	cmd.AddCommand(graph.Cmd)
+	cmd.AddCommand(importers.Cmd)
 	cmd.AddCommand(version.Cmd)
+	cmd.AddCommand(why.Cmd)
 
-	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format (text, mermaid, graphviz or html)")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format (text, mermaid, graphviz, html, json or sarif)")
 	cmd.Flags().StringVarP(&mode, "mode", "m", "forbidden", "check mode (forbidden or allowed)")
 	cmd.Flags().BoolVar(&showMetrics, "metrics", false, "show coupling metrics (overrides config setting)")
+	loadFlags.Register(cmd)
 }
 
-func Run(cfg *config.Config, mode config.Mode, format prints.Format, pattern string) {
-	data, err := parser.ExtractImports(pattern)
+func Run(cfg *config.Config, mode config.Mode, format prints.Format, patterns []string) {
+	loaded, err := loader.Load(cfg, loadFlags, patterns)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
 		os.Exit(1)
 	}
+	data, edges, modulePath, modules := loaded.Graph, loaded.Edges, loaded.ModulePath, loaded.Modules
 
-	modulePath, err := module.Path()
-	if err != nil {
-		fmt.Printf("error: %v\n", err)
-		os.Exit(1)
-	}
-
-	violations := cfg.Validate(data, mode, modulePath)
+	violations := cfg.Validate(loaded.Packages, loaded.Deps, loaded.PackageEdges, mode, modulePath, modules)
+	// stable dependencies are validated even if metrics are not shown, so that their suppressions count as used
+	sdp := cfg.ValidateStableDependencies(data, edges, loaded.Coupling(cfg), modulePath)
+	prints.Warnings(os.Stderr, cfg.Warnings(loaded.Packages, loaded.PackageEdges, mode, slices.Concat(violations, sdp)))
+	// graph printers highlight the edges of the violations, which may be collapsed
+	grouped := loaded.Grouped(violations)
 
 	// calculate coupling metrics if enabled
 	var couplingAnalysis *metrics.CouplingAnalysis
 	if cfg.Metrics.Enabled || showMetrics {
-		couplingAnalysis = metrics.CalculateCoupling(data)
+		// abstractness requires parsing the packages again, so metrics are only calculated for the outputs showing them
+		switch format {
+		case prints.FormatHTML, prints.FormatJSON, prints.FormatText:
+			if couplingAnalysis, err = loaded.Metrics(cfg); err != nil {
+				fmt.Printf("error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		violations = append(violations, sdp...)
+		grouped = append(grouped, sdp...)
 	}
 
 	switch format {
 	case prints.FormatGraphviz:
-		prints.Graphviz(os.Stdout, data, modulePath)
+		prints.Graphviz(os.Stdout, data, edges, modulePath, modules, grouped)
 	case prints.FormatHTML:
 		if (cfg.Metrics.Enabled || showMetrics) && couplingAnalysis != nil {
-			if err := prints.HTMLWithMetrics(os.Stdout, data, modulePath, violations, couplingAnalysis,
-				cfg.Metrics.Coupling.MaxEfferent,
-				cfg.Metrics.Coupling.MaxAfferent,
-				cfg.Metrics.Coupling.MaxInstability); err != nil {
+			if err := prints.HTMLWithMetrics(os.Stdout, data, edges, modulePath, modules, grouped, couplingAnalysis, cfg.Metrics); err != nil {
 				fmt.Printf("error: %v\n", err)
 				os.Exit(1)
 			}
 		} else {
-			if err := prints.HTML(os.Stdout, data, modulePath, violations); err != nil {
+			if err := prints.HTML(os.Stdout, data, edges, modulePath, modules, grouped); err != nil {
 				fmt.Printf("error: %v\n", err)
 				os.Exit(1)
 			}
 		}
+	case prints.FormatJSON:
+		if err := prints.JSON(os.Stdout, data, edges, modulePath, modules, violations, couplingAnalysis); err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
 	case prints.FormatMermaid:
-		prints.Mermaid(os.Stdout, data, modulePath, violations)
+		prints.Mermaid(os.Stdout, data, edges, modulePath, modules, grouped)
+	case prints.FormatSARIF:
+		if err := prints.SARIF(os.Stdout, violations); err != nil {
+			fmt.Printf("error: %v\n", err)
+			os.Exit(1)
+		}
 	case prints.FormatText:
 		if (cfg.Metrics.Enabled || showMetrics) && couplingAnalysis != nil {
-			prints.TextWithMetrics(os.Stdout, data, modulePath, couplingAnalysis,
-				cfg.Metrics.Coupling.MaxEfferent,
-				cfg.Metrics.Coupling.MaxAfferent,
-				cfg.Metrics.Coupling.MaxInstability)
+			prints.TextWithMetrics(os.Stdout, data, edges, modulePath, couplingAnalysis, cfg.Metrics)
 		} else {
-			prints.Text(os.Stdout, data, modulePath)
+			prints.Text(os.Stdout, data, edges, modulePath)
 		}
 	}
 
-	if len(violations) > 0 {
-		_, _ = fmt.Fprintf(os.Stderr, "\n🚨 %d violation(s) found\n\n", len(violations))
-
-		for _, violation := range violations {
-			_, _ = fmt.Fprintln(os.Stderr, "🚨 Violation:", violation.Message)
-		}
-	}
+	prints.Violations(os.Stderr, violations)
 }
 
 func Execute() {
-	if err := cmd.Execute(); err != nil {
+	if c, err := cmd.ExecuteC(); err != nil {
 		fmt.Println(err)
+		// check tells errors apart from violations, usage errors included
+		if c == check.Cmd {
+			os.Exit(check.ExitError)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,122 @@
+package why
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/cli/loader"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+var (
+	all       = false
+	limit     = 20
+	loadFlags loader.Flags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "why <from> <to>",
+	Short: "Explain how one package reaches another",
+	Long: `Print the shortest import path from one package to another, following the imports of dependencies as well.
+
+<from> is loaded like any pattern (e.g. ./internal/handler or ./internal/...), and <to> is a package path,
+either full (e.g. database/sql) or relative to the module path (e.g. internal/repository).
+If <to> is not reachable from <from>, the program will exit with code 1.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+
+		Run(cfg, args[0], args[1])
+		return nil
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&all, "all", false, "print all import paths instead of the shortest one")
+	Cmd.Flags().IntVar(&limit, "limit", 20, "maximum number of paths to print with --all, shortest first (0 for no limit)")
+	loadFlags.Register(Cmd)
+}
+
+func Run(cfg *config.Config, from, to string) {
+	loadFlags.Deps = true
+	loaded, err := loader.Load(cfg, loadFlags, []string{from})
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// packages without any import, or not reachable, are not part of the graph
+	sources, err := loaded.Resolve(from)
+	if err != nil {
+		fmt.Printf("%s does not depend on %s\n", from, to)
+		os.Exit(1)
+	}
+	targets, err := loaded.Resolve(to)
+	if err != nil {
+		fmt.Printf("%s does not depend on %s\n", from, to)
+		os.Exit(1)
+	}
+
+	var paths [][]string
+	for _, source := range sources {
+		for _, target := range targets {
+			if all {
+				paths = append(paths, loaded.Graph.AllPaths(source, target, limit)...)
+			} else if path := loaded.Graph.ShortestPath(source, target); path != nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		fmt.Printf("%s does not depend on %s\n", from, to)
+		os.Exit(1)
+	}
+
+	if !all {
+		paths = [][]string{shortest(paths)}
+	} else {
+		sort.SliceStable(paths, func(i, j int) bool { return len(paths[i]) < len(paths[j]) })
+		if limit > 0 && len(paths) > limit {
+			paths = paths[:limit]
+		}
+	}
+
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Println()
+		}
+		printPath(path, loaded.Edges, loaded.ModulePath)
+	}
+}
+
+func shortest(paths [][]string) []string {
+	found := paths[0]
+	for _, path := range paths[1:] {
+		if len(path) < len(found) {
+			found = path
+		}
+	}
+	return found
+}
+
+// printPath prints one package per line, with the position of the import leading to it if known.
+func printPath(path []string, edges goimportmaps.Edges, modulePath string) {
+	fmt.Println(module.Shorten(path[0], modulePath))
+	for i := 1; i < len(path); i++ {
+		line := "  → " + module.Shorten(path[i], modulePath)
+		if edge := edges.Get(path[i-1], path[i]); edge != nil && len(edge.Positions) > 0 {
+			line += fmt.Sprintf(" (%s)", edge.Positions[0])
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+// Acyclic forbids import cycles among components, i.e. groups of packages.
+type Acyclic struct {
+	Name string `yaml:"name,omitempty"`
+	// Components lists the patterns grouping packages into components; a package belongs to the first one it matches.
+	// If a pattern has capture groups, each distinct value of its first group is a component of its own.
+	Components []string `yaml:"components"`
+	// PatternSyntax overrides the syntax of the Components patterns set for the whole config.
+	PatternSyntax PatternSyntax `yaml:"pattern_syntax,omitempty"`
+
+	CompiledComponents []*regexp.Regexp `yaml:"-"`
+}
+
+func (a *Acyclic) compile(c *compiler) error {
+	syntax, err := c.resolve(a.PatternSyntax)
+	if err != nil {
+		return err
+	}
+	for _, component := range a.Components {
+		componentRegexp, err := c.compile(component, syntax)
+		if err != nil {
+			return fmt.Errorf("invalid acyclic component pattern `%s`: %w", component, err)
+		}
+		a.CompiledComponents = append(a.CompiledComponents, componentRegexp)
+	}
+	return nil
+}
+
+// Component returns the name of the component pkgPath belongs to, or an empty string if none.
+func (a *Acyclic) Component(pkgPath string) string {
+	for i, componentRegexp := range a.CompiledComponents {
+		match := componentRegexp.FindStringSubmatch(pkgPath)
+		if match == nil {
+			continue
+		}
+		if len(match) > 1 {
+			return "[" + match[1] + "]"
+		}
+		return "[" + a.Components[i] + "]"
+	}
+	return ""
+}
+
+// Cycles returns the import cycles involving at least two components of the graph collapsed into components.
+// Each cycle starts and ends with the same component, with packages outside any component (shortened) in between.
+func (a *Acyclic) Cycles(graph goimportmaps.Graph, modulePath string) [][]string {
+	collapsed := graph.Collapse(a.Component)
+
+	var cycles [][]string
+	for _, scc := range collapsed.Cycles() {
+		var components []string
+		for _, node := range scc {
+			if strings.HasPrefix(node, "[") {
+				components = append(components, node)
+			}
+		}
+		if len(components) < 2 {
+			continue
+		}
+
+		cycle := componentCycle(collapsed, scc, components)
+		for i, node := range cycle {
+			cycle[i] = module.Shorten(node, modulePath)
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// componentCycle returns the shortest cycle within the strongly connected component scc that goes from the first of
+// its components through another one, so that it involves at least two components, rather than through packages outside
+// any component only. Among cycles of the same length, the one through the first component in sorted order is returned.
+func componentCycle(graph goimportmaps.Graph, scc, components []string) []string {
+	set := make(map[string]bool, len(scc))
+	for _, node := range scc {
+		set[node] = true
+	}
+	sub := graph.Filter(func(from, to string) bool { return set[from] && set[to] })
+
+	var shortest []string
+	for _, other := range components[1:] {
+		there, back := sub.ShortestPath(components[0], other), sub.ShortestPath(other, components[0])
+		if there == nil || back == nil {
+			continue
+		}
+		if cycle := append(there, back[1:]...); shortest == nil || len(cycle) < len(shortest) {
+			shortest = cycle
+		}
+	}
+	return shortest
+}
+
+// firstImport returns an import between packages of the first two nodes of the cycle, as returned by Cycles,
+// so that the violation can be located, or nil if none.
+func (a *Acyclic) firstImport(graph goimportmaps.Graph, cycle []string, modulePath string) []string {
+	for _, source := range graph.Packages() {
+		if a.node(source, modulePath) != cycle[0] {
+			continue
+		}
+		for _, imprt := range graph[source] {
+			if a.node(imprt, modulePath) == cycle[1] {
+				return []string{source, imprt}
+			}
+		}
+	}
+	return nil
+}
+
+// node returns the node of the cycles returned by Cycles the package belongs to.
+func (a *Acyclic) node(pkg, modulePath string) string {
+	if component := a.Component(pkg); component != "" {
+		return component
+	}
+	return module.Shorten(pkg, modulePath)
+}
+
+func (a *Acyclic) String() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return strings.Join(a.Components, ", ")
+}
+
+// ValidateAcyclic checks the import graph against the acyclic rules.
+func (c *Config) ValidateAcyclic(graph goimportmaps.Graph, modulePath string) []Violation {
+	var violations []Violation
+
+	for i, rule := range c.Acyclic {
+		for _, cycle := range rule.Cycles(graph, modulePath) {
+			violations = append(violations, Violation{
+				RuleID:   fmt.Sprintf("acyclic/%d", i+1),
+				Severity: SeverityError,
+				Rule:     rule.String(),
+				Message:  fmt.Sprintf("import cycle between components: %s (matched rule: %s)", strings.Join(cycle, " → "), rule.String()),
+				Cycle:    cycle,
+				Chain:    rule.firstImport(graph, cycle, modulePath),
+			})
+		}
+	}
+
+	return violations
+}
+
+// Analyzed reports whether the imports of the packages involved in a violation, e.g. of a baseline entry, are known:
+// the source must be one of the importing packages of the graph, and for acyclic violations, every node of the cycle
+// must have one. Violations of rules that no longer exist are always analyzed.
+func (c *Config) Analyzed(graph goimportmaps.Graph, ruleID, source string, cycle []string, modulePath string) bool {
+	if len(cycle) == 0 {
+		_, ok := graph[source]
+		return ok
+	}
+
+	var i int
+	if _, err := fmt.Sscanf(ruleID, "acyclic/%d", &i); err != nil || i < 1 || i > len(c.Acyclic) {
+		return true
+	}
+	rule := &c.Acyclic[i-1]
+
+	nodes := make(map[string]bool)
+	for pkg := range graph {
+		nodes[rule.node(pkg, modulePath)] = true
+	}
+	for _, node := range cycle {
+		if !nodes[node] {
+			return false
+		}
+	}
+	return true
+}
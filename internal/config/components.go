@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+// Component is a named group of packages, which the graph can be collapsed into (see Config.Collapse).
+type Component struct {
+	Name     string `yaml:"name"`
+	Packages string `yaml:"packages"`
+	// PatternSyntax overrides the syntax of the Packages pattern set for the whole config.
+	PatternSyntax PatternSyntax `yaml:"pattern_syntax,omitempty"`
+
+	CompiledPackages *regexp.Regexp `yaml:"-"`
+}
+
+func compileComponents(components []Component, c *compiler) error {
+	seen := make(map[string]bool)
+	for i := range components {
+		component := &components[i]
+
+		if component.Name == "" {
+			return fmt.Errorf("component %d has no name", i+1)
+		}
+		if seen[component.Name] {
+			return fmt.Errorf("duplicate component `%s`", component.Name)
+		}
+		seen[component.Name] = true
+
+		if component.Packages == "" {
+			return fmt.Errorf("component `%s` has no packages pattern", component.Name)
+		}
+		syntax, err := c.resolve(component.PatternSyntax)
+		if err != nil {
+			return err
+		}
+		packagesRegexp, err := c.compile(component.Packages, syntax)
+		if err != nil {
+			return fmt.Errorf("invalid packages pattern `%s` of component `%s`: %w", component.Packages, component.Name, err)
+		}
+		component.CompiledPackages = packagesRegexp
+	}
+	return nil
+}
+
+// Collapse returns the function folding packages into groups for the given `--collapse` value, given the paths
+// of the main modules (see goimportmaps.Graph.Collapse):
+//
+//   - `depth:N` folds the packages of the main modules into their ancestor directory N levels below the module root.
+//   - `components` folds packages into the first component they match.
+//
+// Packages that are not folded, e.g. third-party ones, are kept as is.
+func (c *Config) Collapse(spec string, modules []string) (func(pkg string) string, error) {
+	if spec == "components" {
+		if len(c.Components) == 0 {
+			return nil, fmt.Errorf("cannot collapse into components: none is defined in the config")
+		}
+		return c.componentOf, nil
+	}
+
+	value, ok := strings.CutPrefix(spec, "depth:")
+	if !ok {
+		return nil, fmt.Errorf("invalid collapse: %s (expected depth:N or components)", spec)
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 1 {
+		return nil, fmt.Errorf("invalid collapse depth: %s", value)
+	}
+
+	return func(pkg string) string {
+		mod := module.Of(pkg, modules)
+		if mod == "" || pkg == mod {
+			return ""
+		}
+		dirs := strings.Split(strings.TrimPrefix(pkg, mod+"/"), "/")
+		if len(dirs) <= depth {
+			return ""
+		}
+		return mod + "/" + strings.Join(dirs[:depth], "/")
+	}, nil
+}
+
+// componentOf returns the name of the first component pkgPath belongs to, or an empty string if none.
+func (c *Config) componentOf(pkgPath string) string {
+	for _, component := range c.Components {
+		if component.CompiledPackages.MatchString(pkgPath) {
+			return component.Name
+		}
+	}
+	return ""
+}
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/mickamy/goimportmaps"
 	"github.com/mickamy/goimportmaps/internal/module"
+	"github.com/mickamy/goimportmaps/internal/pattern"
 )
 
 type Mode string
@@ -27,19 +30,28 @@ func NewMode(s string) (Mode, error) {
 	}
 }
 
+// Severity is the severity of the violations of a rule. Only errors fail the check.
+type Severity string
+
 const (
-	path = ".goimportmaps.yaml"
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
 )
 
-type Rule struct {
-	Source  string   `yaml:"source"`
-	Imports []string `yaml:"imports"`
-	Stdlib  *bool    `yaml:"stdlib,omitempty"`
-
-	CompiledSource  *regexp.Regexp   `yaml:"-"`
-	CompiledImports []*regexp.Regexp `yaml:"-"`
+func NewSeverity(s string) (Severity, error) {
+	switch sev := Severity(s); sev {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return sev, nil
+	default:
+		return "", fmt.Errorf("invalid severity: %s", s)
+	}
 }
 
+const (
+	path = ".goimportmaps.yaml"
+)
+
 type CouplingThresholds struct {
 	MaxEfferent     int     `yaml:"max_efferent"`
 	MaxAfferent     int     `yaml:"max_afferent"`
@@ -49,15 +61,130 @@ type CouplingThresholds struct {
 	WarnInstability float64 `yaml:"warn_instability"`
 }
 
+// MetricsScope is the set of packages coupling metrics are computed for, and relative to.
+type MetricsScope string
+
+const (
+	// ScopeModule only takes the packages of the main modules into account.
+	ScopeModule MetricsScope = "module"
+	// ScopeThirdParty takes every package but the standard library into account.
+	ScopeThirdParty MetricsScope = "third_party"
+	ScopeAll        MetricsScope = "all"
+)
+
+func NewMetricsScope(s string) (MetricsScope, error) {
+	switch scope := MetricsScope(s); scope {
+	case ScopeModule, ScopeThirdParty, ScopeAll:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("invalid metrics scope: %s", s)
+	}
+}
+
 type Metrics struct {
 	Coupling CouplingThresholds `yaml:"coupling"`
 	Enabled  bool               `yaml:"enabled"`
+	// Scope is the set of packages metrics are computed for: module (default), third_party or all.
+	Scope MetricsScope `yaml:"scope"`
+	// Check makes the check command enforce the coupling thresholds.
+	Check bool `yaml:"check"`
+	// CheckStableDependencies makes the check command fail on imports of packages less stable than their importer.
+	CheckStableDependencies bool `yaml:"check_stable_dependencies"`
+	// Overrides adjusts the thresholds of some packages; a package uses the first override it matches.
+	Overrides []MetricsOverride `yaml:"overrides"`
+}
+
+// MetricsOverride replaces the non-zero thresholds for the packages matching its pattern,
+// or leaves them out of the threshold checks altogether if Ignore is set.
+type MetricsOverride struct {
+	Packages      string             `yaml:"packages"`
+	PatternSyntax PatternSyntax      `yaml:"pattern_syntax,omitempty"`
+	Ignore        bool               `yaml:"ignore"`
+	Coupling      CouplingThresholds `yaml:"coupling"`
+
+	CompiledPackages *regexp.Regexp `yaml:"-"`
+}
+
+// Include returns whether a package is in the scope of the metrics, given the paths of the main modules.
+func (m *Metrics) Include(modules []string) func(pkg string) bool {
+	switch m.Scope {
+	case ScopeAll:
+		return nil
+	case ScopeThirdParty:
+		return func(pkg string) bool { return !module.IsStdlib(pkg) }
+	default:
+		return func(pkg string) bool { return module.Of(pkg, modules) != "" }
+	}
+}
+
+// Thresholds returns the coupling thresholds of the given package,
+// or false if it is not subject to threshold checks.
+func (m *Metrics) Thresholds(pkgPath string) (CouplingThresholds, bool) {
+	thresholds := m.Coupling
+	for _, override := range m.Overrides {
+		if !override.CompiledPackages.MatchString(pkgPath) {
+			continue
+		}
+		if override.Ignore {
+			return thresholds, false
+		}
+
+		o := override.Coupling
+		if o.MaxEfferent != 0 {
+			thresholds.MaxEfferent = o.MaxEfferent
+		}
+		if o.MaxAfferent != 0 {
+			thresholds.MaxAfferent = o.MaxAfferent
+		}
+		if o.MaxInstability != 0 {
+			thresholds.MaxInstability = o.MaxInstability
+		}
+		if o.WarnEfferent != 0 {
+			thresholds.WarnEfferent = o.WarnEfferent
+		}
+		if o.WarnAfferent != 0 {
+			thresholds.WarnAfferent = o.WarnAfferent
+		}
+		if o.WarnInstability != 0 {
+			thresholds.WarnInstability = o.WarnInstability
+		}
+		break
+	}
+	return thresholds, true
+}
+
+// Tests holds the rules for imports that only appear in test files.
+// If no rules are defined for the selected mode, the top-level rules apply to them as well.
+type Tests struct {
+	// Only lists packages that may only be imported from test files, in the syntax set for the whole config.
+	Only      []string `yaml:"only"`
+	Forbidden []Rule   `yaml:"forbidden"`
+	Allowed   []Rule   `yaml:"allowed"`
+
+	CompiledOnly []*regexp.Regexp `yaml:"-"`
 }
 
 type Config struct {
-	Forbidden []Rule  `yaml:"forbidden"`
-	Allowed   []Rule  `yaml:"allowed"`
-	Metrics   Metrics `yaml:"metrics"`
+	// PatternSyntax is the syntax of the package patterns of the config: regex (default) or glob.
+	PatternSyntax PatternSyntax `yaml:"pattern_syntax"`
+	Forbidden     []Rule        `yaml:"forbidden"`
+	Allowed       []Rule        `yaml:"allowed"`
+	Tests         Tests         `yaml:"tests"`
+	// Acyclic forbids import cycles among groups of packages.
+	Acyclic []Acyclic `yaml:"acyclic"`
+	// Layers lists the layers of the architecture from the top to the bottom.
+	// A package may only import packages of its own layer or lower ones.
+	Layers []Layer `yaml:"layers"`
+	// Components names groups of packages, which the graph can be collapsed into with `--collapse=components`.
+	Components []Component `yaml:"components"`
+	// Builds lists the build configurations (`[GOOS/GOARCH][:tag1,tag2]`) to analyze packages under.
+	Builds []string `yaml:"builds"`
+	// Exclude lists packages to leave out of the analysis, in the syntax set for the whole config (see Exclusions).
+	Exclude []string `yaml:"exclude"`
+	Metrics Metrics  `yaml:"metrics"`
+
+	// compiler compiles the patterns given on the command line, e.g. exclusions, like the ones of the config.
+	compiler *compiler
 }
 
 func Load() (*Config, error) {
@@ -79,40 +206,69 @@ func LoadByPath(path string) (*Config, error) {
 		return nil, fmt.Errorf("invalid config format: %w", err)
 	}
 
-	for i := range cfg.Forbidden {
-		rule := &cfg.Forbidden[i]
+	c, err := newCompiler(cfg.PatternSyntax)
+	if err != nil {
+		return nil, err
+	}
+	cfg.compiler = c
 
-		if rule.CompiledSource, err = regexp.Compile(rule.Source); err != nil {
-			return nil, fmt.Errorf("invalid source regex `%q: %w`", rule.Source, err)
+	for _, rules := range [][]Rule{cfg.Forbidden, cfg.Allowed, cfg.Tests.Forbidden, cfg.Tests.Allowed} {
+		if err := compileRules(rules, c); err != nil {
+			return nil, err
 		}
-		for _, imprt := range rule.Imports {
-			imprtRegexp, err := regexp.Compile(imprt)
-			if err != nil {
-				return nil, fmt.Errorf("invalid import pattern `%s`: %w", imprt, err)
-			}
-			rule.CompiledImports = append(rule.CompiledImports, imprtRegexp)
+	}
+	if err := checkRuleIDs(cfg.Forbidden, cfg.Tests.Forbidden); err != nil {
+		return nil, err
+	}
+	if err := checkAllowedRules(cfg.Allowed, "allowed"); err != nil {
+		return nil, err
+	}
+	if err := checkAllowedRules(cfg.Tests.Allowed, "tests.allowed"); err != nil {
+		return nil, err
+	}
+
+	for i := range cfg.Acyclic {
+		if err := cfg.Acyclic[i].compile(c); err != nil {
+			return nil, err
 		}
 	}
 
-	for i := range cfg.Allowed {
-		rule := &cfg.Allowed[i]
+	if err := compileLayers(cfg.Layers, c); err != nil {
+		return nil, err
+	}
+	if err := compileComponents(cfg.Components, c); err != nil {
+		return nil, err
+	}
 
-		if rule.CompiledSource, err = regexp.Compile(rule.Source); err != nil {
-			return nil, fmt.Errorf("invalid source regex `%q: %w`", rule.Source, err)
+	for i := range cfg.Metrics.Overrides {
+		override := &cfg.Metrics.Overrides[i]
+		syntax, err := c.resolve(override.PatternSyntax)
+		if err != nil {
+			return nil, err
 		}
-		for _, imprt := range rule.Imports {
-			imprtRegexp, err := regexp.Compile(imprt)
-			if err != nil {
-				return nil, fmt.Errorf("invalid import pattern `%s`: %w", imprt, err)
-			}
-			rule.CompiledImports = append(rule.CompiledImports, imprtRegexp)
+		if override.CompiledPackages, err = c.compile(override.Packages, syntax); err != nil {
+			return nil, fmt.Errorf("invalid metrics override pattern `%s`: %w", override.Packages, err)
+		}
+	}
+
+	for _, only := range cfg.Tests.Only {
+		onlyRegexp, err := c.compile(only, c.syntax)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tests only pattern `%s`: %w", only, err)
 		}
+		cfg.Tests.CompiledOnly = append(cfg.Tests.CompiledOnly, onlyRegexp)
 	}
 
 	// set default values for metrics if not specified
 	if !cfg.Metrics.Enabled {
 		cfg.Metrics.Enabled = true
 	}
+	if cfg.Metrics.Scope == "" {
+		cfg.Metrics.Scope = ScopeModule
+	}
+	if _, err := NewMetricsScope(string(cfg.Metrics.Scope)); err != nil {
+		return nil, err
+	}
 	if cfg.Metrics.Coupling.MaxEfferent == 0 {
 		cfg.Metrics.Coupling.MaxEfferent = 10
 	}
@@ -137,8 +293,10 @@ func LoadByPath(path string) (*Config, error) {
 
 func getDefaultConfig() *Config {
 	return &Config{
+		compiler: &compiler{syntax: SyntaxRegex},
 		Metrics: Metrics{
 			Enabled: true,
+			Scope:   ScopeModule,
 			Coupling: CouplingThresholds{
 				MaxEfferent:     10,
 				MaxAfferent:     15,
@@ -152,44 +310,176 @@ func getDefaultConfig() *Config {
 }
 
 type Violation struct {
-	Source  string
-	Import  string
-	Message string
+	Source   string
+	Import   string
+	RuleID   string
+	Rule     string
+	Severity Severity
+	// Message describes the violation, followed by the message of the rule if any.
+	Message   string
+	Positions []goimportmaps.Position
+	// Cycle lists the components of an import cycle, first and last being the same, for acyclic violations.
+	// Source and Import are empty for those.
+	Cycle []string
+	// Chain lists the packages from Source to Import, both included, for transitive violations,
+	// and the packages of the first import of the cycle for acyclic violations.
+	Chain []string
+	// Suppressions lists the comments suppressing the violation for some of its import specs,
+	// whose positions are then removed from Positions (see Suppressed).
+	Suppressions []goimportmaps.Suppression
+}
+
+// Edge returns the import that caused the violation: the first import of the chain for transitive violations.
+func (v Violation) Edge() (string, string) {
+	if len(v.Chain) > 1 {
+		return v.Chain[0], v.Chain[1]
+	}
+	return v.Source, v.Import
+}
+
+// locate fills in the positions of the import specs that caused the violations.
+func locate(violations []Violation, edges goimportmaps.Edges) {
+	for i, v := range violations {
+		if edge := edges.Get(v.Edge()); edge != nil {
+			violations[i].Positions = edge.Positions
+		}
+	}
 }
 
-// Validate checks the import graph against forbidden rules.
-// It returns a slice of human-readable violation messages.
-func (c *Config) Validate(graph goimportmaps.Graph, mode Mode, modulePath string) []Violation {
+// Validate checks the import graph against the rules of the given mode.
+// Imports that only appear in test files are checked against the tests rules,
+// and production imports against the tests only list, the acyclic rules and the layers.
+// Violations suppressed by a comment are returned as well (see Active).
+// Transitive rules walk deps, the production imports of the packages and their dependencies (see HasTransitive),
+// or the production imports of graph if deps is nil.
+func (c *Config) Validate(graph, deps goimportmaps.Graph, edges goimportmaps.Edges, mode Mode, modulePath string, modules []string) []Violation {
+	production := graph.Filter(func(from, to string) bool { return !edges.IsTest(from, to) })
+	tests := graph.Filter(func(from, to string) bool { return edges.IsTest(from, to) })
+	if deps == nil {
+		deps = production
+	}
+
+	var violations []Violation
 	switch mode {
 	case ModeForbidden:
-		return c.ValidateForbidden(graph, modulePath)
+		violations = append(violations, validateForbidden(c.Forbidden, string(ModeForbidden), production, deps, modulePath, modules)...)
+		if len(c.Tests.Forbidden) > 0 {
+			violations = append(violations, validateForbidden(c.Tests.Forbidden, "tests/"+string(ModeForbidden), tests, deps, modulePath, modules)...)
+		} else {
+			violations = append(violations, validateForbidden(c.Forbidden, string(ModeForbidden), tests, deps, modulePath, modules)...)
+		}
 	case ModeAllowed:
-		return c.ValidateAllowed(graph, modulePath)
+		violations = append(violations, c.ValidateAllowed(production, modulePath, modules)...)
+		if len(c.Tests.Allowed) > 0 {
+			violations = append(violations, validateAllowed(c.Tests.Allowed, "tests/"+string(ModeAllowed), tests, modulePath, modules)...)
+		} else {
+			violations = append(violations, c.ValidateAllowed(tests, modulePath, modules)...)
+		}
 	default:
 		panic(fmt.Errorf("invalid mode %s", mode))
 	}
+	violations = append(violations, c.ValidateTestOnly(production, modulePath)...)
+	violations = append(violations, c.ValidateAcyclic(production, modulePath)...)
+	violations = append(violations, c.ValidateLayers(production, modulePath)...)
+
+	locate(violations, edges)
+	suppress(violations, edges)
+
+	return violations
+}
+
+// Exclusions compiles the exclusions of the config, followed by the given ones, in the syntax set for the whole config.
+// With the regex syntax, Go package patterns relative to the module root (e.g. `./internal/gen/...`) are accepted as well.
+func (c *Config) Exclusions(exclude []string, modulePath string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range slices.Concat(c.Exclude, exclude) {
+		var re *regexp.Regexp
+		var err error
+		if c.compiler.syntax == SyntaxRegex && pattern.IsGoPattern(p) {
+			re, err = pattern.Compile(p, modulePath)
+		} else {
+			re, err = c.compiler.compile(p, c.compiler.syntax)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern `%s`: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
 }
 
-func (c *Config) ValidateForbidden(graph goimportmaps.Graph, modulePath string) []Violation {
+// Warnings returns the issues of the config and suppression comments detected on the import graph,
+// given the violations found by Validate, that do not fail validation.
+func (c *Config) Warnings(graph goimportmaps.Graph, edges goimportmaps.Edges, mode Mode, violations []Violation) []string {
+	var warnings []string
+	for _, layer := range c.UnusedLayers(graph) {
+		warnings = append(warnings, fmt.Sprintf("layer %s matches no package", layer))
+	}
+	for _, s := range c.UnusedSuppressions(graph, edges, mode, violations) {
+		warnings = append(warnings, describeSuppression(s))
+	}
+	return warnings
+}
+
+func (c *Config) ValidateForbidden(graph goimportmaps.Graph, modulePath string, modules []string) []Violation {
+	return validateForbidden(c.Forbidden, string(ModeForbidden), graph, graph, modulePath, modules)
+}
+
+// HasTransitive reports whether a forbidden rule is transitive, so that the imports of dependencies must be loaded.
+func (c *Config) HasTransitive() bool {
+	for _, rules := range [][]Rule{c.Forbidden, c.Tests.Forbidden} {
+		for _, rule := range rules {
+			if rule.Transitive {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateForbidden checks the imports of graph against the rules, transitive ones walking the imports of deps
+// from the imports of graph.
+func validateForbidden(rules []Rule, ruleIDPrefix string, graph, deps goimportmaps.Graph, modulePath string, modules []string) []Violation {
 	var violations []Violation
+	var walk goimportmaps.Graph
+	if slices.ContainsFunc(rules, func(rule Rule) bool { return rule.Transitive }) {
+		walk = union(graph, deps)
+	}
 
-	for _, rule := range c.Forbidden {
+	for i, rule := range rules {
 		for source, imports := range graph {
-			if !rule.CompiledSource.MatchString(source) {
+			sourceMatch, ok := rule.matchSource(source, modules)
+			if !ok {
 				continue
 			}
 
+			if rule.Transitive {
+				imports = walk.Reachable(source)
+			}
 			for _, imprt := range imports {
-				for _, imprtRegexp := range rule.CompiledImports {
-					if !imprtRegexp.MatchString(imprt) {
-						continue
+				imprtPattern, ok := rule.matchImport(imprt, sourceMatch, modules)
+				if !ok {
+					continue
+				}
+				matched := rule.describe(imprtPattern)
+				violation := Violation{
+					Source:   source,
+					Import:   imprt,
+					RuleID:   rule.id(ruleIDPrefix, i),
+					Rule:     matched,
+					Severity: rule.severity(),
+					Message:  fmt.Sprintf("%s imports %s (matched rule: %s)", module.Shorten(source, modulePath), module.Shorten(imprt, modulePath), matched),
+				}
+				if rule.Transitive {
+					violation.Chain = walk.ShortestPath(source, imprt)
+					if len(violation.Chain) > 2 {
+						violation.Message = fmt.Sprintf("%s depends on %s through %s (matched rule: %s)", module.Shorten(source, modulePath), module.Shorten(imprt, modulePath), shortenChain(violation.Chain, modulePath), matched)
 					}
-					violations = append(violations, Violation{
-						Source:  source,
-						Import:  imprtRegexp.String(),
-						Message: fmt.Sprintf("%s imports %s (matched rule: %s → %s)", module.Shorten(source, modulePath), module.Shorten(imprt, modulePath), rule.Source, imprtRegexp.String()),
-					})
 				}
+				if rule.Message != "" {
+					violation.Message += ": " + rule.Message
+				}
+				violations = append(violations, violation)
 			}
 		}
 	}
@@ -197,15 +487,43 @@ func (c *Config) ValidateForbidden(graph goimportmaps.Graph, modulePath string)
 	return violations
 }
 
-func (c *Config) ValidateAllowed(graph goimportmaps.Graph, modulePath string) []Violation {
+// union returns the graph of the imports of both graphs.
+func union(a, b goimportmaps.Graph) goimportmaps.Graph {
+	merged := make(goimportmaps.Graph, len(a)+len(b))
+	for _, g := range []goimportmaps.Graph{a, b} {
+		for from, toList := range g {
+			for _, to := range toList {
+				if !slices.Contains(merged[from], to) {
+					merged[from] = append(merged[from], to)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func shortenChain(chain []string, modulePath string) string {
+	shortened := make([]string, len(chain))
+	for i, pkg := range chain {
+		shortened[i] = module.Shorten(pkg, modulePath)
+	}
+	return strings.Join(shortened, " → ")
+}
+
+func (c *Config) ValidateAllowed(graph goimportmaps.Graph, modulePath string, modules []string) []Violation {
+	return validateAllowed(c.Allowed, string(ModeAllowed), graph, modulePath, modules)
+}
+
+func validateAllowed(rules []Rule, ruleID string, graph goimportmaps.Graph, modulePath string, modules []string) []Violation {
 	var violations []Violation
 
 	for source, imports := range graph {
 		for _, imprt := range imports {
 			matched := false
 
-			for _, rule := range c.Allowed {
-				if !rule.CompiledSource.MatchString(source) {
+			for _, rule := range rules {
+				sourceMatch, ok := rule.matchSource(source, modules)
+				if !ok {
 					continue
 				}
 
@@ -219,22 +537,49 @@ func (c *Config) ValidateAllowed(graph goimportmaps.Graph, modulePath string) []
 					break
 				}
 
-				for _, imprtRegexp := range rule.CompiledImports {
-					if imprtRegexp.MatchString(imprt) {
-						matched = true
-						break
-					}
-				}
-				if matched {
+				if _, ok := rule.matchImport(imprt, sourceMatch, modules); ok {
+					matched = true
 					break
 				}
 			}
 
 			if !matched {
 				violations = append(violations, Violation{
-					Source:  source,
-					Import:  imprt,
-					Message: fmt.Sprintf("%s imports %s, but no allowed rule matched", module.Shorten(source, modulePath), module.Shorten(imprt, modulePath)),
+					Source:   source,
+					Import:   imprt,
+					RuleID:   ruleID,
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("%s imports %s, but no allowed rule matched", module.Shorten(source, modulePath), module.Shorten(imprt, modulePath)),
+				})
+			}
+		}
+	}
+
+	return violations
+}
+
+// ValidateTestOnly checks that packages listed in tests.only are not imported by the given (production) graph.
+// Packages matching the same pattern may import each other.
+func (c *Config) ValidateTestOnly(graph goimportmaps.Graph, modulePath string) []Violation {
+	var violations []Violation
+
+	for i, onlyRegexp := range c.Tests.CompiledOnly {
+		for source, imports := range graph {
+			if onlyRegexp.MatchString(source) {
+				continue
+			}
+
+			for _, imprt := range imports {
+				if !onlyRegexp.MatchString(imprt) {
+					continue
+				}
+				violations = append(violations, Violation{
+					Source:   source,
+					Import:   imprt,
+					RuleID:   fmt.Sprintf("tests/only/%d", i+1),
+					Severity: SeverityError,
+					Rule:     c.Tests.Only[i],
+					Message:  fmt.Sprintf("%s imports %s, which may only be imported from tests (matched rule: %s)", module.Shorten(source, modulePath), module.Shorten(imprt, modulePath), c.Tests.Only[i]),
 				})
 			}
 		}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/metrics"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+// ValidateCoupling checks the coupling metrics of every package against its thresholds (see Metrics.Thresholds):
+// exceeding a max_* threshold is an error, and exceeding a warn_* one a warning.
+// A zero warning threshold is disabled.
+func (c *Config) ValidateCoupling(analysis *metrics.CouplingAnalysis, modulePath string) []Violation {
+	var violations []Violation
+
+	packages := make([]string, 0, len(analysis.Packages))
+	for pkg := range analysis.Packages {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		thresholds, ok := c.Metrics.Thresholds(pkg)
+		if !ok {
+			continue
+		}
+		m := analysis.Packages[pkg]
+		short := module.Shorten(pkg, modulePath)
+
+		checks := []struct {
+			metric            string
+			value             float64
+			max, warn         float64
+			format            string
+			maxName, warnName string
+		}{
+			{"efferent coupling (Ce)", float64(m.EfferentCoupling), float64(thresholds.MaxEfferent), float64(thresholds.WarnEfferent), "%.0f", "max_efferent", "warn_efferent"},
+			{"afferent coupling (Ca)", float64(m.AfferentCoupling), float64(thresholds.MaxAfferent), float64(thresholds.WarnAfferent), "%.0f", "max_afferent", "warn_afferent"},
+			{"instability (I)", m.Instability, thresholds.MaxInstability, thresholds.WarnInstability, "%.2f", "max_instability", "warn_instability"},
+		}
+		for _, check := range checks {
+			var threshold float64
+			var id string
+			var severity Severity
+			switch {
+			case check.value > check.max:
+				threshold, id, severity = check.max, check.maxName, SeverityError
+			case check.warn > 0 && check.value > check.warn:
+				threshold, id, severity = check.warn, check.warnName, SeverityWarning
+			default:
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Source:   pkg,
+				RuleID:   "coupling/" + id,
+				Rule:     fmt.Sprintf("%s: "+check.format, id, threshold),
+				Severity: severity,
+				Message:  fmt.Sprintf("%s has %s "+check.format+" > "+check.format+" (%s)", short, check.metric, check.value, threshold, id),
+			})
+		}
+	}
+
+	return violations
+}
+
+// ValidateStableDependencies reports the imports of packages less stable than their importer
+// (see metrics.CouplingAnalysis.UnstableDependencies): errors if CheckStableDependencies is set, warnings otherwise.
+// Violations suppressed by a comment are returned as well (see Active).
+func (c *Config) ValidateStableDependencies(graph goimportmaps.Graph, edges goimportmaps.Edges, analysis *metrics.CouplingAnalysis, modulePath string) []Violation {
+	severity := SeverityWarning
+	if c.Metrics.CheckStableDependencies {
+		severity = SeverityError
+	}
+
+	var violations []Violation
+	for _, d := range analysis.UnstableDependencies(graph) {
+		violation := Violation{
+			Source:   d.Package,
+			Import:   d.Import,
+			RuleID:   "stable_dependencies",
+			Rule:     "I(source) >= I(import)",
+			Severity: severity,
+			Message:  fmt.Sprintf("%s (I=%.2f) depends on less stable %s (I=%.2f)", module.Shorten(d.Package, modulePath), d.PackageInstability, module.Shorten(d.Import, modulePath), d.ImportInstability),
+		}
+		if edge := edges.Get(d.Package, d.Import); edge != nil {
+			violation.Positions = edge.Positions
+		}
+		violations = append(violations, violation)
+	}
+	suppress(violations, edges)
+
+	return violations
+}
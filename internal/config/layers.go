@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+// Layer is one level of a layered architecture, listed from the top (e.g. handler) to the bottom (e.g. model).
+type Layer struct {
+	Name     string `yaml:"name"`
+	Packages string `yaml:"packages"`
+	// PatternSyntax overrides the syntax of the Packages pattern set for the whole config.
+	PatternSyntax PatternSyntax `yaml:"pattern_syntax,omitempty"`
+
+	CompiledPackages *regexp.Regexp `yaml:"-"`
+}
+
+func compileLayers(layers []Layer, c *compiler) error {
+	seen := make(map[string]bool)
+	for i := range layers {
+		layer := &layers[i]
+
+		if layer.Name == "" {
+			return fmt.Errorf("layer %d has no name", i+1)
+		}
+		if seen[layer.Name] {
+			return fmt.Errorf("duplicate layer `%s`", layer.Name)
+		}
+		seen[layer.Name] = true
+
+		if layer.Packages == "" {
+			return fmt.Errorf("layer `%s` has no packages pattern", layer.Name)
+		}
+		syntax, err := c.resolve(layer.PatternSyntax)
+		if err != nil {
+			return err
+		}
+		packagesRegexp, err := c.compile(layer.Packages, syntax)
+		if err != nil {
+			return fmt.Errorf("invalid packages pattern `%s` of layer `%s`: %w", layer.Packages, layer.Name, err)
+		}
+		layer.CompiledPackages = packagesRegexp
+	}
+	return nil
+}
+
+// layerOf returns the index of the first layer pkgPath belongs to, or -1 if none.
+func (c *Config) layerOf(pkgPath string) int {
+	for i, layer := range c.Layers {
+		if layer.CompiledPackages.MatchString(pkgPath) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateLayers checks that packages only import packages of the same or lower layers.
+// Packages outside any layer are not constrained.
+func (c *Config) ValidateLayers(graph goimportmaps.Graph, modulePath string) []Violation {
+	var violations []Violation
+	if len(c.Layers) == 0 {
+		return violations
+	}
+
+	for source, imports := range graph {
+		sourceLayer := c.layerOf(source)
+		if sourceLayer < 0 {
+			continue
+		}
+
+		for _, imprt := range imports {
+			imprtLayer := c.layerOf(imprt)
+			if imprtLayer < 0 || imprtLayer >= sourceLayer {
+				continue
+			}
+			rule := fmt.Sprintf("%s → %s", c.Layers[sourceLayer].Name, c.Layers[imprtLayer].Name)
+			violations = append(violations, Violation{
+				Source:   source,
+				Import:   imprt,
+				RuleID:   "layers",
+				Severity: SeverityError,
+				Rule:     rule,
+				Message:  fmt.Sprintf("%s (layer %s) imports %s of upper layer %s (matched rule: %s)", module.Shorten(source, modulePath), c.Layers[sourceLayer].Name, module.Shorten(imprt, modulePath), c.Layers[imprtLayer].Name, rule),
+			})
+		}
+	}
+
+	return violations
+}
+
+// UnusedLayers returns the names of the layers matching no package of the graph,
+// which usually means that their pattern is wrong.
+func (c *Config) UnusedLayers(graph goimportmaps.Graph) []string {
+	used := make(map[int]bool)
+	for _, pkg := range graph.Packages() {
+		if i := c.layerOf(pkg); i >= 0 {
+			used[i] = true
+		}
+	}
+
+	var unused []string
+	for i, layer := range c.Layers {
+		if !used[i] {
+			unused = append(unused, layer.Name)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+type Rule struct {
+	// ID identifies the violations of the rule, e.g. in suppression comments. Defaults to `forbidden/N`.
+	ID string `yaml:"id,omitempty"`
+	// Severity of the violations of the rule: error (default), warning or info.
+	Severity Severity `yaml:"severity,omitempty"`
+	// Message explains why the rule exists, and is appended to the messages of its violations.
+	Message string `yaml:"message,omitempty"`
+
+	Source string `yaml:"source"`
+	// Imports may refer to the capture groups of Source as `$1` or `${name}`,
+	// e.g. `internal/$1/` to only match the imports of the same component.
+	Imports []string `yaml:"imports"`
+	Stdlib  *bool    `yaml:"stdlib,omitempty"`
+	// SourceModule and ImportsModule restrict the rule to packages of the main modules
+	// whose path matches them, e.g. to tell apart the modules of a go.work workspace.
+	SourceModule  string `yaml:"source_module,omitempty"`
+	ImportsModule string `yaml:"imports_module,omitempty"`
+	// PatternSyntax overrides the syntax of the Source and Imports patterns set for the whole config.
+	PatternSyntax PatternSyntax `yaml:"pattern_syntax,omitempty"`
+	// Transitive makes a forbidden rule match the packages reachable from the source, not only its direct imports.
+	Transitive bool `yaml:"transitive,omitempty"`
+
+	CompiledSource        *regexp.Regexp   `yaml:"-"`
+	CompiledImports       []*regexp.Regexp `yaml:"-"`
+	CompiledSourceModule  *regexp.Regexp   `yaml:"-"`
+	CompiledImportsModule *regexp.Regexp   `yaml:"-"`
+
+	// expanded caches the imports patterns with references, compiled for a given match of the source.
+	expanded map[string]*regexp.Regexp
+}
+
+// referenceRegexp matches the references to capture groups of the source in imports patterns.
+// A `$` followed by anything else, e.g. the end of the pattern, is left as is.
+var referenceRegexp = regexp.MustCompile(`\$(\d+|\{\w+\})`)
+
+func compileRules(rules []Rule, c *compiler) error {
+	for i := range rules {
+		rule := &rules[i]
+
+		syntax, err := c.resolve(rule.PatternSyntax)
+		if err != nil {
+			return err
+		}
+		if rule.Severity != "" {
+			if _, err := NewSeverity(string(rule.Severity)); err != nil {
+				return err
+			}
+		}
+
+		if rule.CompiledSource, err = c.compile(rule.Source, syntax); err != nil {
+			return fmt.Errorf("invalid source pattern `%s`: %w", rule.Source, err)
+		}
+		for _, imprt := range rule.Imports {
+			if syntax != SyntaxRegex && referenceRegexp.MatchString(imprt) {
+				return fmt.Errorf("invalid import pattern `%s`: references to capture groups are only supported by the regex syntax", imprt)
+			}
+			if referenceRegexp.MatchString(imprt) {
+				if err := rule.checkReferences(imprt); err != nil {
+					return err
+				}
+				// compiled once the source is known, see importRegexps
+				rule.CompiledImports = append(rule.CompiledImports, nil)
+				if rule.expanded == nil {
+					rule.expanded = make(map[string]*regexp.Regexp)
+				}
+				continue
+			}
+			imprtRegexp, err := c.compile(imprt, syntax)
+			if err != nil {
+				return fmt.Errorf("invalid import pattern `%s`: %w", imprt, err)
+			}
+			rule.CompiledImports = append(rule.CompiledImports, imprtRegexp)
+		}
+		if rule.SourceModule != "" {
+			if rule.CompiledSourceModule, err = regexp.Compile(rule.SourceModule); err != nil {
+				return fmt.Errorf("invalid source module regex `%s`: %w", rule.SourceModule, err)
+			}
+		}
+		if rule.ImportsModule != "" {
+			if rule.CompiledImportsModule, err = regexp.Compile(rule.ImportsModule); err != nil {
+				return fmt.Errorf("invalid imports module regex `%s`: %w", rule.ImportsModule, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkReferences reports an error if the imports pattern refers to capture groups the source does not have,
+// or if it is not a valid regex once they are substituted.
+func (r *Rule) checkReferences(imprt string) error {
+	for _, ref := range referenceRegexp.FindAllStringSubmatch(imprt, -1) {
+		if r.groupIndex(ref[1]) < 0 {
+			return fmt.Errorf("invalid import pattern `%s`: source `%s` has no capture group %s", imprt, r.Source, ref[0])
+		}
+	}
+	if _, err := regexp.Compile(referenceRegexp.ReplaceAllString(imprt, "x")); err != nil {
+		return fmt.Errorf("invalid import pattern `%s`: %w", imprt, err)
+	}
+	return nil
+}
+
+// groupIndex returns the index of the capture group of the source a reference (`1` or `{name}`) refers to,
+// or -1 if there is none.
+func (r *Rule) groupIndex(ref string) int {
+	if strings.HasPrefix(ref, "{") {
+		ref = strings.Trim(ref, "{}")
+		if i := r.CompiledSource.SubexpIndex(ref); i >= 0 {
+			return i
+		}
+	}
+	i, err := strconv.Atoi(ref)
+	if err != nil || i > r.CompiledSource.NumSubexp() {
+		return -1
+	}
+	return i
+}
+
+// matchSource reports whether the rule applies to the imports of source.
+// It returns the submatches of the source pattern, to match the imports with.
+func (r *Rule) matchSource(source string, modules []string) ([]string, bool) {
+	match := r.CompiledSource.FindStringSubmatch(source)
+	if match == nil {
+		return nil, false
+	}
+	return match, matchModule(r.CompiledSourceModule, source, modules)
+}
+
+// importRegexps returns the compiled imports patterns, with the references substituted
+// by the submatches of the source (quoted).
+func (r *Rule) importRegexps(sourceMatch []string) []*regexp.Regexp {
+	regexps := make([]*regexp.Regexp, len(r.CompiledImports))
+	for i, imprtRegexp := range r.CompiledImports {
+		if imprtRegexp != nil {
+			regexps[i] = imprtRegexp
+			continue
+		}
+
+		expanded := referenceRegexp.ReplaceAllStringFunc(r.Imports[i], func(ref string) string {
+			return regexp.QuoteMeta(sourceMatch[r.groupIndex(ref[1:])])
+		})
+		if r.expanded[expanded] == nil {
+			// the syntax has been checked by checkReferences, and quoted submatches cannot break it
+			r.expanded[expanded] = regexp.MustCompile(expanded)
+		}
+		regexps[i] = r.expanded[expanded]
+	}
+	return regexps
+}
+
+// matchImport returns the pattern of the rule imprt matches, if any, given the submatches of the source.
+func (r *Rule) matchImport(imprt string, sourceMatch []string, modules []string) (string, bool) {
+	if !matchModule(r.CompiledImportsModule, imprt, modules) {
+		return "", false
+	}
+
+	var prefix string
+	if r.CompiledImportsModule != nil {
+		if len(r.CompiledImports) == 0 {
+			return "module " + r.ImportsModule, true
+		}
+		prefix = "module " + r.ImportsModule + " "
+	}
+
+	for i, imprtRegexp := range r.importRegexps(sourceMatch) {
+		if !imprtRegexp.MatchString(imprt) {
+			continue
+		}
+		// patterns with references are described as substituted, others as written (e.g. globs)
+		if r.CompiledImports[i] == nil {
+			return prefix + imprtRegexp.String(), true
+		}
+		return prefix + r.Imports[i], true
+	}
+	return "", false
+}
+
+// describe returns a human-readable representation of the rule, given the import pattern that matched.
+func (r *Rule) describe(imprtPattern string) string {
+	source := r.Source
+	if r.SourceModule != "" {
+		source = "module " + r.SourceModule
+		if r.Source != "" {
+			source += " " + r.Source
+		}
+	}
+	return fmt.Sprintf("%s → %s", source, imprtPattern)
+}
+
+// matchModule reports whether pkgPath belongs to a main module matching moduleRegexp.
+// A nil moduleRegexp matches any package.
+func matchModule(moduleRegexp *regexp.Regexp, pkgPath string, modules []string) bool {
+	if moduleRegexp == nil {
+		return true
+	}
+	m := module.Of(pkgPath, modules)
+	return m != "" && moduleRegexp.MatchString(m)
+}
+
+// id returns the id of the rule: its own, or the prefix followed by its (0-based) index.
+func (r *Rule) id(prefix string, index int) string {
+	if r.ID != "" {
+		return r.ID
+	}
+	return fmt.Sprintf("%s/%d", prefix, index+1)
+}
+
+// severity returns the severity of the violations of the rule, errors by default.
+func (r *Rule) severity() Severity {
+	if r.Severity == "" {
+		return SeverityError
+	}
+	return r.Severity
+}
+
+// checkRuleIDs reports an error if several rules share the same id.
+// checkAllowedRules reports an error if an allowed rule sets a field that only applies to forbidden rules:
+// imports not matched by any allowed rule are violations of the allowed rules as a whole, rather than of one of them.
+func checkAllowedRules(rules []Rule, section string) error {
+	for i, rule := range rules {
+		var field string
+		switch {
+		case rule.ID != "":
+			field = "id"
+		case rule.Severity != "":
+			field = "severity"
+		case rule.Message != "":
+			field = "message"
+		case rule.Transitive:
+			field = "transitive"
+		default:
+			continue
+		}
+		return fmt.Errorf("%s rule %d: %s is only supported by forbidden rules", section, i+1, field)
+	}
+	return nil
+}
+
+func checkRuleIDs(ruleSets ...[]Rule) error {
+	seen := make(map[string]bool)
+	for _, rules := range ruleSets {
+		for _, rule := range rules {
+			if rule.ID == "" {
+				continue
+			}
+			if seen[rule.ID] {
+				return fmt.Errorf("duplicate rule id `%s`", rule.ID)
+			}
+			seen[rule.ID] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/mickamy/goimportmaps"
+)
+
+// Suppressed reports whether every import spec causing the violation is suppressed by a comment.
+func (v Violation) Suppressed() bool {
+	return len(v.Suppressions) > 0 && len(v.Positions) == 0
+}
+
+// Active returns the violations that are not suppressed.
+func Active(violations []Violation) []Violation {
+	var active []Violation
+	for _, v := range violations {
+		if !v.Suppressed() {
+			active = append(active, v)
+		}
+	}
+	return active
+}
+
+// suppress moves the positions of the violations suppressed by a comment for their rule to their suppressions.
+func suppress(violations []Violation, edges goimportmaps.Edges) {
+	for i, v := range violations {
+		edge := edges.Get(v.Edge())
+		if edge == nil || len(edge.Suppressions) == 0 {
+			continue
+		}
+
+		var positions []goimportmaps.Position
+		for _, pos := range v.Positions {
+			suppressed := false
+			for _, s := range edge.Suppressions {
+				if s.Position == pos && s.RuleID == v.RuleID {
+					violations[i].Suppressions = append(violations[i].Suppressions, s)
+					suppressed = true
+				}
+			}
+			if !suppressed {
+				positions = append(positions, pos)
+			}
+		}
+		violations[i].Positions = positions
+	}
+}
+
+// UnusedSuppressions returns the suppression comments of the imports of the graph that suppress no violation,
+// e.g. because the violation has been fixed or the rule id is wrong.
+// Suppressions of the rules of the other mode are not reported.
+func (c *Config) UnusedSuppressions(graph goimportmaps.Graph, edges goimportmaps.Edges, mode Mode, violations []Violation) []goimportmaps.Suppression {
+	type key struct {
+		ruleID  string
+		comment goimportmaps.Position
+	}
+	used := make(map[key]bool)
+	for _, v := range violations {
+		for _, s := range v.Suppressions {
+			used[key{s.RuleID, s.Comment}] = true
+		}
+	}
+
+	var unused []goimportmaps.Suppression
+	reported := make(map[key]bool)
+	for _, from := range graph.Packages() {
+		for _, to := range graph[from] {
+			edge := edges.Get(from, to)
+			if edge == nil {
+				continue
+			}
+			for _, s := range edge.Suppressions {
+				k := key{s.RuleID, s.Comment}
+				if used[k] || reported[k] || c.otherMode(s.RuleID, mode) {
+					continue
+				}
+				reported[k] = true
+				unused = append(unused, s)
+			}
+		}
+	}
+
+	slices.SortFunc(unused, func(a, b goimportmaps.Suppression) int {
+		if c := cmp.Compare(a.Comment.File, b.Comment.File); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Comment.Line, b.Comment.Line)
+	})
+	return unused
+}
+
+// otherMode reports whether the rule id belongs to the rules of the mode not being validated,
+// whose suppressions cannot be used.
+func (c *Config) otherMode(ruleID string, mode Mode) bool {
+	if mode == ModeForbidden {
+		return ruleID == string(ModeAllowed) || ruleID == "tests/"+string(ModeAllowed)
+	}
+
+	for i, rule := range c.Forbidden {
+		if rule.id(string(ModeForbidden), i) == ruleID {
+			return true
+		}
+	}
+	for i, rule := range c.Tests.Forbidden {
+		if rule.id("tests/"+string(ModeForbidden), i) == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+func describeSuppression(s goimportmaps.Suppression) string {
+	if s.RuleID == "" {
+		return fmt.Sprintf("%s: suppression without rule id", s.Comment)
+	}
+	return fmt.Sprintf("%s: unused suppression of %s", s.Comment, s.RuleID)
+}
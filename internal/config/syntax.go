@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mickamy/goimportmaps/internal/module"
+	"github.com/mickamy/goimportmaps/internal/pattern"
+)
+
+// PatternSyntax is the syntax of the package patterns of rules and layers.
+type PatternSyntax string
+
+const (
+	SyntaxRegex PatternSyntax = "regex"
+	SyntaxGlob  PatternSyntax = "glob"
+)
+
+// validate reports an error if the syntax is not supported. An empty syntax stands for the default one.
+func (s PatternSyntax) validate() error {
+	switch s {
+	case "", SyntaxRegex, SyntaxGlob:
+		return nil
+	default:
+		return fmt.Errorf("invalid pattern syntax: %s", s)
+	}
+}
+
+// compiler compiles the package patterns of a config.
+type compiler struct {
+	// syntax is the default syntax, for rules not specifying one.
+	syntax PatternSyntax
+	// modulePath is resolved on first use, as only glob patterns relative to the module root need it.
+	modulePath *string
+}
+
+func newCompiler(syntax PatternSyntax) (*compiler, error) {
+	if err := syntax.validate(); err != nil {
+		return nil, err
+	}
+	if syntax == "" {
+		syntax = SyntaxRegex
+	}
+	return &compiler{syntax: syntax}, nil
+}
+
+// resolve returns the syntax to use for a rule, given its own, if any.
+func (c *compiler) resolve(syntax PatternSyntax) (PatternSyntax, error) {
+	if err := syntax.validate(); err != nil {
+		return "", err
+	}
+	if syntax == "" {
+		return c.syntax, nil
+	}
+	return syntax, nil
+}
+
+// compile compiles a package pattern in the given syntax.
+func (c *compiler) compile(p string, syntax PatternSyntax) (*regexp.Regexp, error) {
+	if syntax == SyntaxRegex {
+		return regexp.Compile(p)
+	}
+
+	if c.modulePath == nil && pattern.IsGoPattern(p) {
+		modules, err := module.Modules()
+		if err != nil {
+			return nil, err
+		}
+		modulePath := module.Root(module.Paths(modules))
+		c.modulePath = &modulePath
+	}
+	var modulePath string
+	if c.modulePath != nil {
+		modulePath = *c.modulePath
+	}
+	return pattern.CompileGlob(p, modulePath), nil
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/baseline"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/metrics"
+)
+
+// Snapshot is the import graph of a revision, along with its violations and coupling metrics.
+type Snapshot struct {
+	Graph      goimportmaps.Graph
+	Violations []config.Violation
+	Metrics    *metrics.CouplingAnalysis
+}
+
+// Import is an edge of the import graph.
+type Import struct {
+	From string
+	To   string
+}
+
+// MetricsDelta holds the coupling metrics of a package present in both revisions, whose metrics changed.
+type MetricsDelta struct {
+	Package string
+	Base    metrics.CouplingMetrics
+	Head    metrics.CouplingMetrics
+}
+
+// Result lists the changes from a base revision to a head one, every list being sorted.
+type Result struct {
+	AddedPackages   []string
+	RemovedPackages []string
+	AddedImports    []Import
+	RemovedImports  []Import
+	// NewViolations lists the active violations of the head revision that the base one does not have.
+	NewViolations []config.Violation
+	// FixedViolations lists the active violations of the base revision that the head one no longer has.
+	FixedViolations []baseline.Entry
+	Metrics         []MetricsDelta
+}
+
+// Empty reports whether the revisions have the same graph, violations and metrics.
+func (r *Result) Empty() bool {
+	return len(r.AddedPackages) == 0 && len(r.RemovedPackages) == 0 &&
+		len(r.AddedImports) == 0 && len(r.RemovedImports) == 0 &&
+		len(r.NewViolations) == 0 && len(r.FixedViolations) == 0 &&
+		len(r.Metrics) == 0
+}
+
+// Compare returns the changes from the base snapshot to the head one.
+// Violations are told apart like baseline entries, i.e. regardless of their positions,
+// and those of the packages removed from the head revision are fixed.
+func Compare(base, head Snapshot) *Result {
+	result := &Result{}
+
+	basePackages, headPackages := base.Graph.Packages(), head.Graph.Packages()
+	result.AddedPackages = missing(headPackages, set(basePackages))
+	result.RemovedPackages = missing(basePackages, set(headPackages))
+
+	result.AddedImports = missingImports(head.Graph, base.Graph)
+	result.RemovedImports = missingImports(base.Graph, head.Graph)
+
+	result.NewViolations, result.FixedViolations = baseline.New(config.Active(base.Violations)).Subtract(config.Active(head.Violations), nil)
+
+	if base.Metrics != nil && head.Metrics != nil {
+		for pkg, h := range head.Metrics.Packages {
+			b, ok := base.Metrics.Packages[pkg]
+			if ok && b != h {
+				result.Metrics = append(result.Metrics, MetricsDelta{Package: pkg, Base: b, Head: h})
+			}
+		}
+		sort.Slice(result.Metrics, func(i, j int) bool { return result.Metrics[i].Package < result.Metrics[j].Package })
+	}
+
+	return result
+}
+
+func set(packages []string) map[string]bool {
+	s := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		s[pkg] = true
+	}
+	return s
+}
+
+// missing returns the packages not in the given set, keeping their order.
+func missing(packages []string, in map[string]bool) []string {
+	var found []string
+	for _, pkg := range packages {
+		if !in[pkg] {
+			found = append(found, pkg)
+		}
+	}
+	return found
+}
+
+// missingImports returns the imports of graph that other does not have, sorted.
+func missingImports(graph, other goimportmaps.Graph) []Import {
+	var imports []Import
+	for from, toList := range graph {
+		for _, to := range toList {
+			if !slices.Contains(other[from], to) {
+				imports = append(imports, Import{From: from, To: to})
+			}
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].From != imports[j].From {
+			return imports[i].From < imports[j].From
+		}
+		return imports[i].To < imports[j].To
+	})
+	return imports
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Prefix returns the path of the current directory relative to the root of its repository,
+// with a trailing slash unless it is the root itself.
+func Prefix() (string, error) {
+	return run("rev-parse", "--show-prefix")
+}
+
+// Worktree checks out the given revision in a temporary worktree, detached from any branch,
+// and returns its root directory along with the function removing it.
+func Worktree(ref string) (string, func() error, error) {
+	if _, err := run("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return "", nil, fmt.Errorf("unknown revision: %s", ref)
+	}
+
+	tmp, err := os.MkdirTemp("", "goimportmaps-")
+	if err != nil {
+		return "", nil, err
+	}
+	dir := filepath.Join(tmp, "worktree")
+
+	if _, err := run("worktree", "add", "--detach", dir, ref); err != nil {
+		_ = os.RemoveAll(tmp)
+		return "", nil, err
+	}
+
+	remove := func() error {
+		if _, err := run("worktree", "remove", "--force", dir); err != nil {
+			return err
+		}
+		return os.RemoveAll(tmp)
+	}
+	return dir, remove, nil
+}
+
+func run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"math"
+	"sort"
+
 	"github.com/mickamy/goimportmaps"
 )
 
@@ -9,6 +12,8 @@ type CouplingMetrics struct {
 	AfferentCoupling int     // Ca - Number of packages that depend on this package
 	EfferentCoupling int     // Ce - Number of packages this package depends on
 	Instability      float64 // I = Ce / (Ca + Ce)
+	Abstractness     float64 // A = abstract types / total types
+	Distance         float64 // D = |A + I - 1|, distance from the main sequence
 }
 
 // PackageMetrics holds metrics for a single package
@@ -22,16 +27,30 @@ type CouplingAnalysis struct {
 	Packages map[string]CouplingMetrics
 }
 
-// CalculateCoupling analyzes the dependency graph and calculates coupling metrics
-func CalculateCoupling(graph goimportmaps.Graph) *CouplingAnalysis {
+// TypeCounts holds the number of types declared by a package
+type TypeCounts struct {
+	Abstract int // interfaces
+	Total    int
+}
+
+// CalculateCoupling analyzes the dependency graph and calculates coupling metrics.
+// Only the packages for which include returns true, and the imports between them, are taken into account;
+// a nil include takes every package into account.
+func CalculateCoupling(graph goimportmaps.Graph, include func(pkg string) bool) *CouplingAnalysis {
 	analysis := &CouplingAnalysis{
 		Packages: make(map[string]CouplingMetrics),
 	}
 
-	// Initialize all packages
+	// Initialize all packages, including those whose imports are all out of scope
 	allPackages := getAllPackages(graph)
 	for _, pkg := range allPackages {
-		analysis.Packages[pkg] = CouplingMetrics{}
+		if include == nil || include(pkg) {
+			analysis.Packages[pkg] = CouplingMetrics{}
+		}
+	}
+
+	if include != nil {
+		graph = graph.Filter(func(from, to string) bool { return include(from) && include(to) })
 	}
 
 	// Calculate efferent coupling (Ce) - direct from graph
@@ -65,6 +84,57 @@ func CalculateCoupling(graph goimportmaps.Graph) *CouplingAnalysis {
 	return analysis
 }
 
+// CalculateAbstractness calculates the abstractness and distance from the main sequence of the packages,
+// given the types they declare. Packages declaring no type are considered concrete (A = 0).
+func (a *CouplingAnalysis) CalculateAbstractness(types map[string]TypeCounts) {
+	for pkg, metrics := range a.Packages {
+		counts := types[pkg]
+		if counts.Total > 0 {
+			metrics.Abstractness = float64(counts.Abstract) / float64(counts.Total)
+		} else {
+			metrics.Abstractness = 0.0
+		}
+		metrics.Distance = math.Abs(metrics.Abstractness + metrics.Instability - 1)
+		a.Packages[pkg] = metrics
+	}
+}
+
+// UnstableDependency is an import of a package less stable than its importer,
+// which violates the Stable Dependencies Principle (depend in the direction of stability).
+type UnstableDependency struct {
+	Package            string
+	Import             string
+	PackageInstability float64
+	ImportInstability  float64
+}
+
+// UnstableDependencies returns the imports of the graph whose target is less stable than their source
+// (I(source) < I(target)), sorted. Only the imports between packages of the analysis are taken into account.
+func (a *CouplingAnalysis) UnstableDependencies(graph goimportmaps.Graph) []UnstableDependency {
+	var dependencies []UnstableDependency
+	for _, pkg := range graph.Packages() {
+		source, ok := a.Packages[pkg]
+		if !ok {
+			continue
+		}
+		imports := append([]string(nil), graph[pkg]...)
+		sort.Strings(imports)
+		for _, imprt := range imports {
+			target, ok := a.Packages[imprt]
+			if !ok || source.Instability >= target.Instability {
+				continue
+			}
+			dependencies = append(dependencies, UnstableDependency{
+				Package:            pkg,
+				Import:             imprt,
+				PackageInstability: source.Instability,
+				ImportInstability:  target.Instability,
+			})
+		}
+	}
+	return dependencies
+}
+
 // getAllPackages extracts all unique packages from the graph
 func getAllPackages(graph goimportmaps.Graph) []string {
 	packageSet := make(map[string]bool)
@@ -88,21 +158,3 @@ func getAllPackages(graph goimportmaps.Graph) []string {
 
 	return packages
 }
-
-// GetHighCouplingPackages returns packages with coupling above thresholds
-func (a *CouplingAnalysis) GetHighCouplingPackages(maxEfferent, maxAfferent int, maxInstability float64) []PackageMetrics {
-	var highCoupling []PackageMetrics
-
-	for pkg, metrics := range a.Packages {
-		if metrics.EfferentCoupling > maxEfferent ||
-			metrics.AfferentCoupling > maxAfferent ||
-			metrics.Instability > maxInstability {
-			highCoupling = append(highCoupling, PackageMetrics{
-				Package:  pkg,
-				Coupling: metrics,
-			})
-		}
-	}
-
-	return highCoupling
-}
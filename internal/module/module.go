@@ -2,19 +2,101 @@ package module
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 )
 
-// Path returns the current module name (e.g., github.com/xxx/yyy).
-func Path() (string, error) {
-	cmd := exec.Command("go", "list", "-m")
-	var out bytes.Buffer
+// Module is a main module: the current module, or a module of the go.work workspace.
+type Module struct {
+	Path string
+	Dir  string
+}
+
+// Modules returns the main modules (one per `use` directive in workspace mode).
+func Modules() ([]Module, error) {
+	cmd := exec.Command("go", "list", "-m", "-json")
+	var out, stderr bytes.Buffer
 	cmd.Stdout = &out
+	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("go list -m: %s", msg)
+		}
+		return nil, fmt.Errorf("go list -m: %w", err)
+	}
+
+	var modules []Module
+	decoder := json.NewDecoder(&out)
+	for {
+		var m Module
+		if err := decoder.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode go list output: %w", err)
+		}
+		modules = append(modules, m)
+	}
+
+	return modules, nil
+}
+
+// Path returns the path package paths are shortened against (e.g., github.com/xxx/yyy):
+// the current module path, or the longest common path of the workspace modules.
+func Path() (string, error) {
+	modules, err := Modules()
+	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(out.String()), nil
+	return Root(Paths(modules)), nil
+}
+
+// Paths returns the paths of the modules.
+func Paths(modules []Module) []string {
+	paths := make([]string, 0, len(modules))
+	for _, m := range modules {
+		paths = append(paths, m.Path)
+	}
+	return paths
+}
+
+// Root returns the longest common path of the given module paths, split on path elements.
+func Root(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	root := strings.Split(paths[0], "/")
+	for _, path := range paths[1:] {
+		elems := strings.Split(path, "/")
+		n := 0
+		for n < len(root) && n < len(elems) && root[n] == elems[n] {
+			n++
+		}
+		root = root[:n]
+	}
+	return strings.Join(root, "/")
+}
+
+// Of returns the path of the module pkgPath belongs to, or an empty string if it is not part of any of them.
+// Nested modules take precedence over their parents.
+func Of(pkgPath string, paths []string) string {
+	var found string
+	for _, path := range paths {
+		if Contains(path, pkgPath) && len(path) > len(found) {
+			found = path
+		}
+	}
+	return found
+}
+
+// Contains reports whether pkgPath is prefix itself or one of its subpackages.
+func Contains(prefix, pkgPath string) bool {
+	return pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/")
 }
 
 func IsStdlib(path string) bool {
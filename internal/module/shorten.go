@@ -5,7 +5,7 @@ import (
 )
 
 func Shorten(pkgPath, modulePath string) string {
-	if strings.HasPrefix(pkgPath, modulePath) {
+	if modulePath != "" && Contains(modulePath, pkgPath) {
 		rel := strings.TrimPrefix(pkgPath, modulePath)
 		rel = strings.TrimPrefix(rel, "/")
 		return rel
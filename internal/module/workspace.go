@@ -0,0 +1,64 @@
+package module
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPatterns rewrites relative recursive patterns (e.g. `./...`) whose directory is not inside any of the modules
+// into one pattern per module below it. The go command rejects such patterns in a go.work workspace
+// whose root is not a module itself.
+func ExpandPatterns(patterns []string, modules []Module) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	var expanded []string
+	for _, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "./") || !strings.HasSuffix(pattern, "/...") {
+			expanded = append(expanded, pattern)
+			continue
+		}
+
+		dir := filepath.Join(wd, filepath.FromSlash(strings.TrimSuffix(pattern, "/...")))
+		if inAnyModule(dir, modules) {
+			expanded = append(expanded, pattern)
+			continue
+		}
+
+		var found bool
+		for _, m := range modules {
+			if !isWithin(m.Dir, dir) {
+				continue
+			}
+			rel, err := filepath.Rel(wd, m.Dir)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, "./"+filepath.ToSlash(rel)+"/...")
+			found = true
+		}
+		if !found {
+			expanded = append(expanded, pattern) // let the go command report it
+		}
+	}
+
+	return expanded, nil
+}
+
+func inAnyModule(dir string, modules []Module) bool {
+	for _, m := range modules {
+		if isWithin(dir, m.Dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is dir itself or one of its descendants.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
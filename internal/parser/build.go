@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Build is a build configuration packages are loaded under.
+// Empty fields fall back to the host build context.
+type Build struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+// ParseBuild parses a build configuration in the form `[GOOS/GOARCH][:tag1,tag2]`,
+// e.g. `linux/amd64`, `windows/amd64:integration` or `:integration`.
+func ParseBuild(s string) (Build, error) {
+	platform, tags, _ := strings.Cut(s, ":")
+
+	var build Build
+	if platform != "" {
+		goos, goarch, ok := strings.Cut(platform, "/")
+		if !ok || goos == "" || goarch == "" {
+			return Build{}, fmt.Errorf("invalid build `%s`: platform must be GOOS/GOARCH", s)
+		}
+		build.GOOS, build.GOARCH = goos, goarch
+	}
+	if tags != "" {
+		build.Tags = strings.Split(tags, ",")
+	}
+	if build.GOOS == "" && len(build.Tags) == 0 {
+		return Build{}, fmt.Errorf("invalid build `%s`: neither platform nor tags specified", s)
+	}
+
+	return build, nil
+}
+
+// ParseBuilds parses each of the given build configurations.
+func ParseBuilds(specs []string) ([]Build, error) {
+	builds := make([]Build, 0, len(specs))
+	for _, spec := range specs {
+		build, err := ParseBuild(spec)
+		if err != nil {
+			return nil, err
+		}
+		builds = append(builds, build)
+	}
+	return builds, nil
+}
+
+func (b Build) String() string {
+	var s string
+	if b.GOOS != "" {
+		s = b.GOOS + "/" + b.GOARCH
+	}
+	if len(b.Tags) > 0 {
+		s += ":" + strings.Join(b.Tags, ",")
+	}
+	return s
+}
+
+// env returns the environment to run the go command with.
+func (b Build) env() []string {
+	if b.GOOS == "" {
+		return nil // inherit the current environment
+	}
+	return append(os.Environ(), "GOOS="+b.GOOS, "GOARCH="+b.GOARCH)
+}
+
+// flags returns the build flags to run the go command with.
+func (b Build) flags() []string {
+	if len(b.Tags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(b.Tags, ",")}
+}
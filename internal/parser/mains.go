@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// MainPackages loads the packages matching the patterns and returns the paths of the main ones (commands), sorted.
+// The main packages of every build configuration are returned.
+func MainPackages(patterns []string, opts Options) ([]string, error) {
+	mains := make(map[string]bool)
+
+	builds := opts.Builds
+	if len(builds) == 0 {
+		builds = []Build{{}}
+	}
+	for _, build := range builds {
+		cfg := &packages.Config{
+			Mode:       packages.NeedName,
+			Env:        build.env(),
+			BuildFlags: build.flags(),
+		}
+
+		pkgs, err := packages.Load(cfg, patterns...)
+		if err != nil {
+			if build.String() != "" {
+				return nil, fmt.Errorf("build %s: failed to load packages: %w", build, err)
+			}
+			return nil, fmt.Errorf("failed to load packages: %w", err)
+		}
+		for _, pkg := range pkgs {
+			if pkg.Name == "main" && pkg.PkgPath != "" {
+				mains[pkg.PkgPath] = true
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(mains))
+	for pkg := range mains {
+		paths = append(paths, pkg)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -1,38 +1,259 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 
 	"github.com/mickamy/goimportmaps"
 )
 
-// ExtractImports loads Go packages and extracts import relationships.
-func ExtractImports(pattern string) (goimportmaps.Graph, error) {
+// Options configures how packages are loaded.
+type Options struct {
+	// Tests includes test files and external test packages in the analysis.
+	Tests bool
+	// Builds lists the build configurations to load packages under; their results are merged.
+	// If empty, packages are loaded under the host build context only.
+	Builds []Build
+	// Deps includes the imports of every dependency of the matched packages, forming the transitive graph.
+	Deps bool
+}
+
+// ExtractImports loads Go packages and extracts import relationships,
+// along with the positions of the import specs they come from.
+func ExtractImports(patterns []string, opts Options) (goimportmaps.Graph, goimportmaps.Edges, error) {
+	edges := make(goimportmaps.Edges)
+
+	if len(opts.Builds) == 0 {
+		if err := load(&collector{edges: edges}, patterns, opts, Build{}); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, build := range opts.Builds {
+		if err := load(&collector{edges: edges, build: build.String()}, patterns, opts, build); err != nil {
+			return nil, nil, fmt.Errorf("build %s: %w", build, err)
+		}
+	}
+
+	for _, imports := range edges {
+		for _, edge := range imports {
+			edge.Test = len(edge.Positions) > 0
+			for _, pos := range edge.Positions {
+				if !isTestFile(pos.File) {
+					edge.Test = false
+					break
+				}
+			}
+		}
+	}
+
+	return edges.Graph(), edges, nil
+}
+
+// collector records the edges found while loading packages under a single build configuration.
+type collector struct {
+	edges goimportmaps.Edges
+	build string
+}
+
+// add returns the edge between the given packages, annotated with the build configuration.
+func (c *collector) add(from, to string) *goimportmaps.Edge {
+	edge := c.edges.Add(from, to)
+	if c.build != "" && !slices.Contains(edge.Builds, c.build) {
+		edge.Builds = append(edge.Builds, c.build)
+	}
+	return edge
+}
+
+func load(c *collector, patterns []string, opts Options, build Build) error {
 	cfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Mode:       packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Tests:      opts.Tests,
+		Env:        build.env(),
+		BuildFlags: build.flags(),
 	}
 
-	pkgs, err := packages.Load(cfg, pattern)
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load packages: %w", err)
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no packages matching %s", strings.Join(patterns, " "))
+	}
+	if err := loadErrors(pkgs); err != nil {
+		return err
 	}
-
-	graph := make(goimportmaps.Graph)
 
 	for _, pkg := range pkgs {
 		if pkg.PkgPath == "" {
 			continue // skip unnamed packages
 		}
+		if strings.HasSuffix(pkg.PkgPath, ".test") {
+			continue // skip generated test main packages
+		}
+
+		if isTestVariant(pkg) {
+			// the non-test files are already covered by the package itself
+			if err := extractPositions(c, pkg, isTestFile); err != nil {
+				return err
+			}
+			continue
+		}
 
 		for _, imp := range pkg.Imports {
 			if imp.PkgPath == "" {
 				continue
 			}
-			graph[pkg.PkgPath] = append(graph[pkg.PkgPath], imp.PkgPath)
+			c.add(pkg.PkgPath, imp.PkgPath)
+		}
+
+		if err := extractPositions(c, pkg, func(string) bool { return true }); err != nil {
+			return err
 		}
 	}
 
-	return graph, nil
+	if opts.Deps {
+		roots := make(map[*packages.Package]bool, len(pkgs))
+		for _, pkg := range pkgs {
+			roots[pkg] = true
+		}
+		var visitErr error
+		packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+			if roots[pkg] || pkg.PkgPath == "" || visitErr != nil {
+				return
+			}
+			for _, imp := range pkg.Imports {
+				if imp.PkgPath != "" {
+					c.add(pkg.PkgPath, imp.PkgPath)
+				}
+			}
+			if pkg.Module != nil && pkg.Module.Main {
+				// positions are only worth it for packages we own
+				visitErr = extractPositions(c, pkg, func(string) bool { return true })
+			}
+		})
+		if visitErr != nil {
+			return visitErr
+		}
+	}
+
+	return nil
+}
+
+// isTestVariant reports whether pkg is a package augmented with its test files
+// (e.g. "p [p.test]") or an external test package (e.g. "p_test [p.test]").
+func isTestVariant(pkg *packages.Package) bool {
+	return pkg.ID != pkg.PkgPath
+}
+
+func isTestFile(filename string) bool {
+	return strings.HasSuffix(filename, "_test.go")
+}
+
+// sourcePackage returns the package path an import of pkg is attributed to;
+// imports of an external test package are attributed to the package under test.
+func sourcePackage(pkg *packages.Package) string {
+	if isTestVariant(pkg) {
+		return strings.TrimSuffix(pkg.PkgPath, "_test")
+	}
+	return pkg.PkgPath
+}
+
+// extractPositions parses the import declarations of the package files accepted by include
+// and records where each import appears.
+func extractPositions(c *collector, pkg *packages.Package, include func(filename string) bool) error {
+	fset := token.NewFileSet()
+	source := sourcePackage(pkg)
+
+	for _, filename := range pkg.GoFiles {
+		if !include(filename) {
+			continue
+		}
+
+		file, err := goparser.ParseFile(fset, filename, nil, goparser.ImportsOnly|goparser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		fileLevel := fileDirectives(fset, file)
+
+		for _, spec := range file.Imports {
+			imp := importedPackage(pkg, spec)
+			if imp == nil {
+				continue // e.g. import "C"
+			}
+			if imp.PkgPath == source {
+				continue // external test package importing the package under test
+			}
+
+			pos := fset.Position(spec.Pos())
+			position := goimportmaps.Position{
+				File:   relativePath(pos.Filename),
+				Line:   pos.Line,
+				Column: pos.Column,
+			}
+			edge := c.add(source, imp.PkgPath)
+			if !slices.Contains(edge.Positions, position) {
+				// the same file may be part of several build configurations
+				edge.Positions = append(edge.Positions, position)
+			}
+			for _, d := range append(specDirectives(fset, file, spec), fileLevel...) {
+				suppression := goimportmaps.Suppression{RuleID: d.ruleID, Reason: d.reason, Position: position, Comment: d.comment}
+				if !slices.Contains(edge.Suppressions, suppression) {
+					edge.Suppressions = append(edge.Suppressions, suppression)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// importedPackage resolves the package an import spec refers to.
+func importedPackage(pkg *packages.Package, spec *ast.ImportSpec) *packages.Package {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return nil
+	}
+	imp, ok := pkg.Imports[path]
+	if !ok || imp.PkgPath == "" {
+		return nil
+	}
+	return imp
+}
+
+// relativePath returns filename relative to the working directory if possible.
+func relativePath(filename string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil {
+		return filename
+	}
+	return rel
+}
+
+// loadErrors returns the errors met while loading the given packages, e.g. missing directories or syntax errors,
+// as a single error, or nil if they all loaded.
+func loadErrors(pkgs []*packages.Package) error {
+	var errs []error
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to load packages: %w", errors.Join(errs...))
 }
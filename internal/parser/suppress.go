@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/mickamy/goimportmaps"
+)
+
+const ignoreDirective = "//goimportmaps:ignore"
+
+// directive is a parsed suppression comment.
+type directive struct {
+	ruleID  string
+	reason  string
+	comment goimportmaps.Position
+}
+
+// fileDirectives returns the suppression comments placed before the package clause of the file.
+func fileDirectives(fset *token.FileSet, file *ast.File) []directive {
+	var groups []*ast.CommentGroup
+	for _, group := range file.Comments {
+		if group.End() < file.Package {
+			groups = append(groups, group)
+		}
+	}
+	return directives(fset, groups...)
+}
+
+// specDirectives returns the suppression comments attached to an import spec: above or next to it,
+// or above the import declaration if it has no parentheses.
+func specDirectives(fset *token.FileSet, file *ast.File, spec *ast.ImportSpec) []directive {
+	groups := []*ast.CommentGroup{spec.Doc, spec.Comment}
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && !gen.Lparen.IsValid() && len(gen.Specs) == 1 && gen.Specs[0] == spec {
+			groups = append(groups, gen.Doc)
+		}
+	}
+	return directives(fset, groups...)
+}
+
+func directives(fset *token.FileSet, groups ...*ast.CommentGroup) []directive {
+	var result []directive
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, comment := range group.List {
+			rest, ok := strings.CutPrefix(comment.Text, ignoreDirective)
+			if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+				continue
+			}
+			ruleID, reason, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			pos := fset.Position(comment.Pos())
+			result = append(result, directive{
+				ruleID: ruleID,
+				reason: strings.TrimSpace(reason),
+				comment: goimportmaps.Position{
+					File:   relativePath(pos.Filename),
+					Line:   pos.Line,
+					Column: pos.Column,
+				},
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/mickamy/goimportmaps/internal/metrics"
+)
+
+// CountTypes counts the types declared at package level by the packages matching the patterns, interfaces being abstract.
+// Only the type declarations of the packages are parsed, without type-checking them or loading their dependencies,
+// so a type defined as an interface of another package is counted as concrete.
+// Test files are not taken into account, and the types of every build configuration are merged.
+func CountTypes(patterns []string, opts Options) (map[string]metrics.TypeCounts, error) {
+	// package path -> type name -> whether it is abstract
+	declared := make(map[string]map[string]bool)
+
+	builds := opts.Builds
+	if len(builds) == 0 {
+		builds = []Build{{}}
+	}
+	for _, build := range builds {
+		if err := loadTypes(declared, patterns, build); err != nil {
+			if build.String() != "" {
+				return nil, fmt.Errorf("build %s: %w", build, err)
+			}
+			return nil, err
+		}
+	}
+
+	counts := make(map[string]metrics.TypeCounts, len(declared))
+	for pkg, names := range declared {
+		var c metrics.TypeCounts
+		for _, abstract := range names {
+			c.Total++
+			if abstract {
+				c.Abstract++
+			}
+		}
+		counts[pkg] = c
+	}
+	return counts, nil
+}
+
+func loadTypes(declared map[string]map[string]bool, patterns []string, build Build) error {
+	cfg := &packages.Config{
+		Mode:       packages.NeedName | packages.NeedFiles,
+		Env:        build.env(),
+		BuildFlags: build.flags(),
+	}
+
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return fmt.Errorf("failed to load packages: %w", err)
+	}
+	if err := loadErrors(pkgs); err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == "" {
+			continue
+		}
+		if declared[pkg.PkgPath] == nil {
+			declared[pkg.PkgPath] = make(map[string]bool)
+		}
+
+		specs, err := typeSpecs(pkg.GoFiles)
+		if err != nil {
+			return err
+		}
+		for name, spec := range specs {
+			if spec.Assign.IsValid() {
+				continue // aliases do not declare a type
+			}
+			declared[pkg.PkgPath][name] = isInterface(spec.Type, specs, make(map[string]bool))
+		}
+	}
+
+	return nil
+}
+
+// typeSpecs parses the given files and returns their package-level type declarations by name, aliases included.
+func typeSpecs(filenames []string) (map[string]*ast.TypeSpec, error) {
+	fset := token.NewFileSet()
+	specs := make(map[string]*ast.TypeSpec)
+	for _, filename := range filenames {
+		file, err := goparser.ParseFile(fset, filename, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name == "_" {
+					continue
+				}
+				specs[typeSpec.Name.Name] = typeSpec
+			}
+		}
+	}
+	return specs, nil
+}
+
+// isInterface reports whether a type expression denotes an interface,
+// following the types defined as, or aliases of, other types of the same package.
+func isInterface(expr ast.Expr, specs map[string]*ast.TypeSpec, seen map[string]bool) bool {
+	switch t := expr.(type) {
+	case *ast.InterfaceType:
+		return true
+	case *ast.ParenExpr:
+		return isInterface(t.X, specs, seen)
+	case *ast.Ident:
+		spec, ok := specs[t.Name]
+		if !ok || seen[t.Name] {
+			return false
+		}
+		seen[t.Name] = true
+		return isInterface(spec.Type, specs, seen)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IsGoPattern reports whether the pattern is a Go package pattern relative to the module root (e.g. `./internal/...`).
+func IsGoPattern(pattern string) bool {
+	return pattern == "." || strings.HasPrefix(pattern, "./")
+}
+
+// Compile compiles a package pattern into a regular expression matching full package paths.
+// Go package patterns (see IsGoPattern) are resolved against the module path, with `...` matching any string
+// as in the go command; anything else is a regular expression.
+func Compile(pattern, modulePath string) (*regexp.Regexp, error) {
+	if !IsGoPattern(pattern) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern `%s`: %w", pattern, err)
+		}
+		return re, nil
+	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(pattern, "."), "/")
+	path := modulePath
+	if rel != "" {
+		path += "/" + rel
+	}
+
+	// like the go command, `x/...` matches `x` as well as its subpackages
+	var suffix string
+	if strings.HasSuffix(path, "/...") {
+		path = strings.TrimSuffix(path, "/...")
+		suffix = "(/.*)?"
+	}
+
+	parts := strings.Split(path, "...")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + suffix + "$"), nil
+}
+
+// CompileGlob compiles a glob package pattern into a regular expression matching full package paths.
+// Patterns starting with `./` are resolved against the module path. The whole path must match:
+// `*` matches any string within a path element, `**` any number of path elements, and `...` any string
+// (`x/...` matching `x` as well as its subpackages, as in the go command).
+// The regular expression has no capture group.
+func CompileGlob(pattern, modulePath string) *regexp.Regexp {
+	path := pattern
+	if IsGoPattern(pattern) {
+		path = modulePath
+		if rel := strings.TrimPrefix(strings.TrimPrefix(pattern, "."), "/"); rel != "" {
+			path += "/" + rel
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for path != "" {
+		switch {
+		case strings.HasPrefix(path, "**/"):
+			b.WriteString("(?:.*/)?")
+			path = path[len("**/"):]
+		case path == "/**" || path == "/...":
+			b.WriteString("(?:/.*)?")
+			path = ""
+		case strings.HasPrefix(path, "**"):
+			b.WriteString(".*")
+			path = path[len("**"):]
+		case strings.HasPrefix(path, "..."):
+			b.WriteString(".*")
+			path = path[len("..."):]
+		case strings.HasPrefix(path, "*"):
+			b.WriteString("[^/]*")
+			path = path[1:]
+		default:
+			next := strings.IndexAny(path[1:], "*./") + 1
+			if next == 0 {
+				next = len(path)
+			}
+			b.WriteString(regexp.QuoteMeta(path[:next]))
+			path = path[next:]
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
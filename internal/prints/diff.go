@@ -0,0 +1,88 @@
+package prints
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mickamy/goimportmaps/internal/diff"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+// Diff writes the changes from a base revision to a head one, section by section, leaving out empty sections.
+func Diff(w io.Writer, result *diff.Result, modulePath string) {
+	if result.Empty() {
+		_, _ = fmt.Fprintln(w, "✅ No architectural changes")
+		return
+	}
+
+	if len(result.AddedPackages) > 0 || len(result.RemovedPackages) > 0 {
+		_, _ = fmt.Fprintf(w, "📦 Packages (+%d, -%d)\n", len(result.AddedPackages), len(result.RemovedPackages))
+		for _, pkg := range result.AddedPackages {
+			_, _ = fmt.Fprintf(w, "  + %s\n", module.Shorten(pkg, modulePath))
+		}
+		for _, pkg := range result.RemovedPackages {
+			_, _ = fmt.Fprintf(w, "  - %s\n", module.Shorten(pkg, modulePath))
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(result.AddedImports) > 0 || len(result.RemovedImports) > 0 {
+		_, _ = fmt.Fprintf(w, "🔗 Imports (+%d, -%d)\n", len(result.AddedImports), len(result.RemovedImports))
+		for _, imp := range result.AddedImports {
+			_, _ = fmt.Fprintf(w, "  + %s --> %s\n", module.Shorten(imp.From, modulePath), module.Shorten(imp.To, modulePath))
+		}
+		for _, imp := range result.RemovedImports {
+			_, _ = fmt.Fprintf(w, "  - %s --> %s\n", module.Shorten(imp.From, modulePath), module.Shorten(imp.To, modulePath))
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(result.NewViolations) > 0 {
+		_, _ = fmt.Fprintf(w, "🚨 New violations (%d)\n", len(result.NewViolations))
+		for _, v := range result.NewViolations {
+			for _, line := range violationLines(v) {
+				_, _ = fmt.Fprintln(w, " ", severityEmoji(v.Severity), line)
+			}
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(result.FixedViolations) > 0 {
+		_, _ = fmt.Fprintf(w, "🧹 Fixed violations (%d)\n", len(result.FixedViolations))
+		for _, entry := range result.FixedViolations {
+			_, _ = fmt.Fprintln(w, "  ✅", entry.Message)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if len(result.Metrics) > 0 {
+		_, _ = fmt.Fprintf(w, "📊 Coupling metrics (%d package(s) changed)\n", len(result.Metrics))
+		_, _ = fmt.Fprintf(w, "  %-50s %-9s %-9s %-13s %-13s %s\n", "Package", "Ca", "Ce", "I", "A", "D")
+		for _, delta := range result.Metrics {
+			_, _ = fmt.Fprintf(w, "  %-50s %-9s %-9s %-13s %-13s %s\n",
+				module.Shorten(delta.Package, modulePath),
+				intDelta(delta.Base.AfferentCoupling, delta.Head.AfferentCoupling),
+				intDelta(delta.Base.EfferentCoupling, delta.Head.EfferentCoupling),
+				floatDelta(delta.Base.Instability, delta.Head.Instability),
+				floatDelta(delta.Base.Abstractness, delta.Head.Abstractness),
+				floatDelta(delta.Base.Distance, delta.Head.Distance),
+			)
+		}
+	}
+}
+
+// intDelta returns `base → head`, or the value alone if unchanged.
+func intDelta(base, head int) string {
+	if base == head {
+		return fmt.Sprint(head)
+	}
+	return fmt.Sprintf("%d → %d", base, head)
+}
+
+// floatDelta returns `base → head` with two decimals, or the value alone if unchanged.
+func floatDelta(base, head float64) string {
+	if fmt.Sprintf("%.2f", base) == fmt.Sprintf("%.2f", head) {
+		return fmt.Sprintf("%.2f", head)
+	}
+	return fmt.Sprintf("%.2f → %.2f", base, head)
+}
@@ -9,7 +9,9 @@ type Format string
 const (
 	FormatGraphviz Format = "graphviz"
 	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
 	FormatMermaid  Format = "mermaid"
+	FormatSARIF    Format = "sarif"
 	FormatText     Format = "text"
 )
 
@@ -19,8 +21,12 @@ func NewFormat(format string) (Format, error) {
 		return FormatGraphviz, nil
 	case string(FormatHTML):
 		return FormatHTML, nil
+	case string(FormatJSON):
+		return FormatJSON, nil
 	case string(FormatMermaid):
 		return FormatMermaid, nil
+	case string(FormatSARIF):
+		return FormatSARIF, nil
 	case string(FormatText):
 		return FormatText, nil
 	default:
@@ -4,14 +4,29 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/config"
 	"github.com/mickamy/goimportmaps/internal/module"
 )
 
-func Graphviz(w io.Writer, graph goimportmaps.Graph, modulePath string) {
+func Graphviz(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string, modules []string, violations []config.Violation) {
 	_, _ = fmt.Fprintln(w, "digraph G {")
 
+	violationMap := make(map[string]map[string][]string)
+	// errorMap tells apart the edges with errors (red) from those with warnings or infos only (orange)
+	errorMap := make(map[string]map[string]bool)
+	for _, v := range config.Active(violations) {
+		from, to := v.Edge()
+		if violationMap[from] == nil {
+			violationMap[from] = make(map[string][]string)
+			errorMap[from] = make(map[string]bool)
+		}
+		violationMap[from][to] = append(violationMap[from][to], violationLines(v)...)
+		errorMap[from][to] = errorMap[from][to] || v.Severity == config.SeverityError
+	}
+
 	keys := make([]string, 0, len(graph))
 	for k := range graph {
 		keys = append(keys, k)
@@ -22,11 +37,31 @@ func Graphviz(w io.Writer, graph goimportmaps.Graph, modulePath string) {
 		toList := graph[from]
 		sort.Strings(toList)
 		for _, to := range toList {
-			from = module.Shorten(from, modulePath)
-			to = module.Shorten(to, modulePath)
-			_, _ = fmt.Fprintf(w, "  %q -> %q;\n", from, to)
+			shortFrom := module.Shorten(from, modulePath)
+			shortTo := module.Shorten(to, modulePath)
+			var attrs []string
+			if edges.IsTest(from, to) {
+				attrs = append(attrs, "style=dashed")
+			}
+			if edge := edges.Get(from, to); edge != nil && edge.Weight > 0 {
+				attrs = append(attrs, fmt.Sprintf("label=%d", edge.Weight))
+			}
+			if lines, ok := violationMap[from][to]; ok {
+				color := "red"
+				if !errorMap[from][to] {
+					color = "orange"
+				}
+				attrs = append(attrs, "color="+color, fmt.Sprintf("tooltip=%q", strings.Join(lines, "\n")))
+			}
+			if len(attrs) > 0 {
+				_, _ = fmt.Fprintf(w, "  %q -> %q [%s];\n", shortFrom, shortTo, strings.Join(attrs, ", "))
+			} else {
+				_, _ = fmt.Fprintf(w, "  %q -> %q;\n", shortFrom, shortTo)
+			}
 		}
 	}
 
+	writeGraphvizModules(w, graph, modulePath, modules)
+
 	_, _ = fmt.Fprintln(w, "}")
 }
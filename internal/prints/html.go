@@ -103,11 +103,42 @@ var htmlTemplateWithMetrics = `<!DOCTYPE html>
         .instability-high {
             background: #dc2626;
         }
+        .chart {
+            background: #fff;
+            padding: 1rem;
+            border: 1px solid #ddd;
+            border-radius: 8px;
+            margin-bottom: 2rem;
+        }
+        .chart svg {
+            display: block;
+            max-width: 480px;
+            width: 100%;
+            height: auto;
+        }
+        .chart text {
+            font-size: 11px;
+            fill: #6b7280;
+        }
+        .chart .point-low {
+            fill: #16a34a;
+        }
+        .chart .point-medium {
+            fill: #eab308;
+        }
+        .chart .point-high {
+            fill: #dc2626;
+        }
         .violation-reasons {
             font-size: 0.85rem;
             margin-top: 0.25rem;
             color: #dc2626;
         }
+        .violations {
+            color: #dc2626;
+            font-size: 0.9rem;
+            margin-bottom: 2rem;
+        }
         .summary {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
@@ -166,6 +197,22 @@ var htmlTemplateWithMetrics = `<!DOCTYPE html>
 </div>
 {{ end }}
 
+{{ if .Violations }}
+<h2>🚨 Import Violations</h2>
+<ul class="violations">
+    {{ range .Violations }}<li><code>{{ . }}</code></li>
+    {{ end }}
+</ul>
+{{ end }}
+
+{{ if .Suppressed }}
+<h2>🔕 Suppressed Violations</h2>
+<ul class="violations">
+    {{ range .Suppressed }}<li><code>{{ . }}</code></li>
+    {{ end }}
+</ul>
+{{ end }}
+
 <h2>📊 Dependency Graph</h2>
 <div class="mermaid">
     {{ .Graph | safe }}
@@ -181,6 +228,8 @@ var htmlTemplateWithMetrics = `<!DOCTYPE html>
                 <th>Ca</th>
                 <th>Ce</th>
                 <th>Instability</th>
+                <th>Abstractness</th>
+                <th>Distance</th>
                 <th>Status</th>
             </tr>
         </thead>
@@ -204,12 +253,31 @@ var htmlTemplateWithMetrics = `<!DOCTYPE html>
                     </div>
                     {{ end }}
                 </td>
+                <td>{{ printf "%.2f" .Abstractness }}</td>
+                <td>{{ printf "%.2f" .Distance }}</td>
                 <td class="status">{{ if .HasViolation }}🚨{{ else }}✅{{ end }}</td>
             </tr>
             {{ end }}
         </tbody>
     </table>
 </div>
+
+<h2>🎯 Abstractness vs Instability</h2>
+<div class="chart">
+    <svg viewBox="0 0 {{ .Chart.Size }} {{ .Chart.Size }}" xmlns="http://www.w3.org/2000/svg">
+        <rect x="{{ .Chart.Margin }}" y="{{ .Chart.Margin }}" width="{{ .Chart.Plot }}" height="{{ .Chart.Plot }}" fill="none" stroke="#ddd" />
+        <line x1="{{ .Chart.Margin }}" y1="{{ .Chart.Margin }}" x2="{{ .Chart.End }}" y2="{{ .Chart.End }}" stroke="#9ca3af" stroke-dasharray="4 4" />
+        <text x="{{ .Chart.Margin }}" y="{{ .Chart.End }}" dx="4" dy="-6">zone of pain</text>
+        <text x="{{ .Chart.End }}" y="{{ .Chart.Margin }}" dx="-4" dy="14" text-anchor="end">zone of uselessness</text>
+        <text x="{{ .Chart.Middle }}" y="{{ .Chart.Size }}" dy="-8" text-anchor="middle">Instability (I)</text>
+        <text x="12" y="{{ .Chart.Middle }}" text-anchor="middle" transform="rotate(-90 12 {{ .Chart.Middle }})">Abstractness (A)</text>
+        {{ range .Chart.Points }}
+        <circle cx="{{ printf "%.1f" .X }}" cy="{{ printf "%.1f" .Y }}" r="5" class="{{ .Class }}">
+            <title>{{ .Package }} (I={{ printf "%.2f" .Instability }}, A={{ printf "%.2f" .Abstractness }}, D={{ printf "%.2f" .Distance }})</title>
+        </circle>
+        {{ end }}
+    </svg>
+</div>
 {{ end }}
 
 <script>
@@ -218,7 +286,7 @@ var htmlTemplateWithMetrics = `<!DOCTYPE html>
 </body>
 </html>`
 
-func HTML(w io.Writer, graph goimportmaps.Graph, modulePath string, violations []config.Violation) error {
+func HTML(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string, modules []string, violations []config.Violation) error {
 	var buf bytes.Buffer
 	buf.WriteString("graph TD\n")
 
@@ -234,13 +302,18 @@ func HTML(w io.Writer, graph goimportmaps.Graph, modulePath string, violations [
 		toList := graph[from]
 		sort.Strings(toList)
 		for _, to := range toList {
-			from = module.Shorten(from, modulePath)
-			to = module.Shorten(to, modulePath)
-			_, _ = fmt.Fprintf(&buf, "  %s --> %s\n", from, to)
+			shortFrom := module.Shorten(from, modulePath)
+			shortTo := module.Shorten(to, modulePath)
+			_, _ = fmt.Fprintf(&buf, "  %s %s %s\n", shortFrom, mermaidArrow(edges, from, to), shortTo)
 		}
 	}
 
-	for _, v := range violations {
+	writeMermaidModules(&buf, graph, modulePath, modules)
+
+	for _, v := range config.Active(violations) {
+		if v.Source == "" {
+			continue // not tied to a single import, e.g. cycles
+		}
 		violationSet[module.Shorten(v.Source, modulePath)] = true
 		violationSet[module.Shorten(v.Import, modulePath)] = true
 	}
@@ -266,7 +339,9 @@ func HTML(w io.Writer, graph goimportmaps.Graph, modulePath string, violations [
 
 	if err := tmpl.Execute(w, htmlTemplateData{
 		Graph:          buf.String(),
-		ViolationCount: len(violations),
+		ViolationCount: len(config.Active(violations)),
+		Violations:     htmlViolations(config.Active(violations)),
+		Suppressed:     htmlSuppressed(violations),
 	}); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
@@ -277,6 +352,26 @@ func HTML(w io.Writer, graph goimportmaps.Graph, modulePath string, violations [
 type htmlTemplateData struct {
 	Graph          string
 	ViolationCount int
+	Violations     []string
+	Suppressed     []string
+}
+
+// htmlViolations returns one line per offending import spec.
+func htmlViolations(violations []config.Violation) []string {
+	var lines []string
+	for _, v := range violations {
+		lines = append(lines, violationLines(v)...)
+	}
+	return lines
+}
+
+// htmlSuppressed returns one line per import spec whose violation is suppressed by a comment.
+func htmlSuppressed(violations []config.Violation) []string {
+	var lines []string
+	for _, v := range violations {
+		lines = append(lines, suppressedLines(v)...)
+	}
+	return lines
 }
 
 type PackageMetricsData struct {
@@ -284,6 +379,8 @@ type PackageMetricsData struct {
 	AfferentCoupling int
 	EfferentCoupling int
 	Instability      float64
+	Abstractness     float64
+	Distance         float64
 	HasViolation     bool
 	ViolationReasons []string
 }
@@ -291,12 +388,54 @@ type PackageMetricsData struct {
 type htmlTemplateDataWithMetrics struct {
 	Graph              string
 	ViolationCount     int
+	Violations         []string
+	Suppressed         []string
 	PackageMetrics     []PackageMetricsData
 	HasMetrics         bool
 	CouplingViolations int
+	Chart              scatterChart
 }
 
-func HTMLWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, violations []config.Violation, analysis *metrics.CouplingAnalysis, maxEfferent, maxAfferent int, maxInstability float64) error {
+// scatterChart plots the packages by instability (x) and abstractness (y), the dashed line being the main sequence (A + I = 1).
+type scatterChart struct {
+	Size, Margin, Plot, Middle, End int
+	Points                          []scatterPoint
+}
+
+type scatterPoint struct {
+	Package                             string
+	Instability, Abstractness, Distance float64
+	X, Y                                float64
+	Class                               string
+}
+
+func newScatterChart(packages []PackageMetricsData) scatterChart {
+	const size, margin = 400, 40
+	plot := size - 2*margin
+	chart := scatterChart{Size: size, Margin: margin, Plot: plot, Middle: margin + plot/2, End: margin + plot}
+
+	for _, pkg := range packages {
+		class := "point-high"
+		switch {
+		case pkg.Distance < 0.3:
+			class = "point-low"
+		case pkg.Distance < 0.7:
+			class = "point-medium"
+		}
+		chart.Points = append(chart.Points, scatterPoint{
+			Package:      pkg.Package,
+			Instability:  pkg.Instability,
+			Abstractness: pkg.Abstractness,
+			Distance:     pkg.Distance,
+			X:            float64(margin) + pkg.Instability*float64(plot),
+			Y:            float64(margin) + (1-pkg.Abstractness)*float64(plot),
+			Class:        class,
+		})
+	}
+	return chart
+}
+
+func HTMLWithMetrics(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string, modules []string, violations []config.Violation, analysis *metrics.CouplingAnalysis, metricsConfig config.Metrics) error {
 	var buf bytes.Buffer
 	buf.WriteString("graph TD\n")
 
@@ -314,17 +453,22 @@ func HTMLWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, v
 		for _, to := range toList {
 			shortFrom := module.Shorten(from, modulePath)
 			shortTo := module.Shorten(to, modulePath)
-			_, _ = fmt.Fprintf(&buf, "  %s --> %s\n", shortFrom, shortTo)
+			_, _ = fmt.Fprintf(&buf, "  %s %s %s\n", shortFrom, mermaidArrow(edges, from, to), shortTo)
 		}
 	}
 
-	for _, v := range violations {
+	writeMermaidModules(&buf, graph, modulePath, modules)
+
+	for _, v := range config.Active(violations) {
+		if v.Source == "" {
+			continue // not tied to a single import, e.g. cycles
+		}
 		violationSet[module.Shorten(v.Source, modulePath)] = true
 		violationSet[module.Shorten(v.Import, modulePath)] = true
 	}
 
 	// add coupling violation nodes
-	couplingViolations := analysis.GetHighCouplingPackages(maxEfferent, maxAfferent, maxInstability)
+	couplingViolations := highCouplingPackages(analysis, metricsConfig)
 	for _, pkg := range couplingViolations {
 		violationSet[module.Shorten(pkg.Package, modulePath)] = true
 	}
@@ -354,19 +498,24 @@ func HTMLWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, v
 			metrics := analysis.Packages[pkg]
 			shortPkg := module.Shorten(pkg, modulePath)
 
-			hasViolation := metrics.EfferentCoupling > maxEfferent ||
-				metrics.AfferentCoupling > maxAfferent ||
-				metrics.Instability > maxInstability
+			thresholds, checked := metricsConfig.Thresholds(pkg)
+			maxEfferent, maxAfferent, maxInstability := thresholds.MaxEfferent, thresholds.MaxAfferent, thresholds.MaxInstability
+			hasViolation := checked && exceedsThresholds(metrics, thresholds)
 
 			var reasons []string
-			if metrics.EfferentCoupling > maxEfferent {
-				reasons = append(reasons, fmt.Sprintf("High efferent coupling (%d > %d)", metrics.EfferentCoupling, maxEfferent))
-			}
-			if metrics.AfferentCoupling > maxAfferent {
-				reasons = append(reasons, fmt.Sprintf("High afferent coupling (%d > %d)", metrics.AfferentCoupling, maxAfferent))
-			}
-			if metrics.Instability > maxInstability {
-				reasons = append(reasons, fmt.Sprintf("High instability (%.2f > %.2f)", metrics.Instability, maxInstability))
+			switch {
+			case !checked:
+				reasons = append(reasons, "Thresholds ignored")
+			default:
+				if metrics.EfferentCoupling > maxEfferent {
+					reasons = append(reasons, fmt.Sprintf("High efferent coupling (%d > %d)", metrics.EfferentCoupling, maxEfferent))
+				}
+				if metrics.AfferentCoupling > maxAfferent {
+					reasons = append(reasons, fmt.Sprintf("High afferent coupling (%d > %d)", metrics.AfferentCoupling, maxAfferent))
+				}
+				if metrics.Instability > maxInstability {
+					reasons = append(reasons, fmt.Sprintf("High instability (%.2f > %.2f)", metrics.Instability, maxInstability))
+				}
 			}
 
 			packageMetrics = append(packageMetrics, PackageMetricsData{
@@ -374,6 +523,8 @@ func HTMLWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, v
 				AfferentCoupling: metrics.AfferentCoupling,
 				EfferentCoupling: metrics.EfferentCoupling,
 				Instability:      metrics.Instability,
+				Abstractness:     metrics.Abstractness,
+				Distance:         metrics.Distance,
 				HasViolation:     hasViolation,
 				ViolationReasons: reasons,
 			})
@@ -390,10 +541,13 @@ func HTMLWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, v
 
 	if err := tmpl.Execute(w, htmlTemplateDataWithMetrics{
 		Graph:              buf.String(),
-		ViolationCount:     len(violations),
+		ViolationCount:     len(config.Active(violations)),
+		Violations:         htmlViolations(config.Active(violations)),
+		Suppressed:         htmlSuppressed(violations),
 		PackageMetrics:     packageMetrics,
 		HasMetrics:         analysis != nil,
 		CouplingViolations: len(couplingViolations),
+		Chart:              newScatterChart(packageMetrics),
 	}); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
 	}
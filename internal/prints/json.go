@@ -0,0 +1,179 @@
+package prints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/config"
+	"github.com/mickamy/goimportmaps/internal/metrics"
+)
+
+// JSONSchemaVersion is bumped whenever a backward incompatible change is made to the JSON output.
+const JSONSchemaVersion = 1
+
+type jsonReport struct {
+	Version    int             `json:"version"`
+	Module     string          `json:"module"`
+	Modules    []string        `json:"modules"`
+	Edges      []jsonEdge      `json:"edges"`
+	Violations []jsonViolation `json:"violations"`
+	Metrics    []jsonMetrics   `json:"metrics,omitempty"`
+}
+
+type jsonEdge struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Test      bool           `json:"test"`
+	Builds    []string       `json:"builds,omitempty"`
+	Positions []jsonPosition `json:"positions"`
+	// Weight is the number of package imports a collapsed edge stands for.
+	Weight int `json:"weight,omitempty"`
+}
+
+type jsonPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type jsonViolation struct {
+	Source    string         `json:"source"`
+	Import    string         `json:"import"`
+	RuleID    string         `json:"rule_id"`
+	Rule      string         `json:"rule"`
+	Severity  string         `json:"severity"`
+	Message   string         `json:"message"`
+	Positions []jsonPosition `json:"positions"`
+	Cycle     []string       `json:"cycle,omitempty"`
+	Chain     []string       `json:"chain,omitempty"`
+	// Suppressed is true if every import spec causing the violation is suppressed by a comment.
+	Suppressed   bool              `json:"suppressed"`
+	Suppressions []jsonSuppression `json:"suppressions,omitempty"`
+}
+
+type jsonSuppression struct {
+	RuleID   string       `json:"rule_id"`
+	Reason   string       `json:"reason"`
+	Position jsonPosition `json:"position"`
+	Comment  jsonPosition `json:"comment"`
+}
+
+type jsonMetrics struct {
+	Package          string  `json:"package"`
+	AfferentCoupling int     `json:"afferent_coupling"`
+	EfferentCoupling int     `json:"efferent_coupling"`
+	Instability      float64 `json:"instability"`
+	Abstractness     float64 `json:"abstractness"`
+	Distance         float64 `json:"distance"`
+}
+
+// JSON writes the graph, violations and coupling metrics (if any) as a single JSON document.
+// Package paths are written in full so that the output does not depend on the module path.
+func JSON(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string, modules []string, violations []config.Violation, analysis *metrics.CouplingAnalysis) error {
+	report := jsonReport{
+		Version:    JSONSchemaVersion,
+		Module:     modulePath,
+		Modules:    modules,
+		Edges:      []jsonEdge{},
+		Violations: []jsonViolation{},
+	}
+
+	keys := make([]string, 0, len(graph))
+	for from := range graph {
+		keys = append(keys, from)
+	}
+	sort.Strings(keys)
+
+	for _, from := range keys {
+		toList := graph[from]
+		sort.Strings(toList)
+		for _, to := range toList {
+			edge := jsonEdge{From: from, To: to, Positions: []jsonPosition{}}
+			if e := edges.Get(from, to); e != nil {
+				edge.Test = e.Test
+				edge.Builds = e.Builds
+				edge.Positions = jsonPositions(e.Positions)
+				edge.Weight = e.Weight
+			}
+			report.Edges = append(report.Edges, edge)
+		}
+	}
+
+	for _, v := range violations {
+		report.Violations = append(report.Violations, jsonViolation{
+			Source:    v.Source,
+			Import:    v.Import,
+			RuleID:    v.RuleID,
+			Rule:      v.Rule,
+			Severity:  string(v.Severity),
+			Message:   v.Message,
+			Positions: jsonPositions(v.Positions),
+			Cycle:     v.Cycle,
+			Chain:     v.Chain,
+
+			Suppressed:   v.Suppressed(),
+			Suppressions: jsonSuppressions(v.Suppressions),
+		})
+	}
+
+	if analysis != nil {
+		packages := make([]string, 0, len(analysis.Packages))
+		for pkg := range analysis.Packages {
+			packages = append(packages, pkg)
+		}
+		sort.Strings(packages)
+
+		for _, pkg := range packages {
+			m := analysis.Packages[pkg]
+			report.Metrics = append(report.Metrics, jsonMetrics{
+				Package:          pkg,
+				AfferentCoupling: m.AfferentCoupling,
+				EfferentCoupling: m.EfferentCoupling,
+				Instability:      m.Instability,
+				Abstractness:     m.Abstractness,
+				Distance:         m.Distance,
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode json: %w", err)
+	}
+
+	return nil
+}
+
+func jsonPositions(positions []goimportmaps.Position) []jsonPosition {
+	result := make([]jsonPosition, 0, len(positions))
+	for _, pos := range positions {
+		result = append(result, toJSONPosition(pos))
+	}
+	return result
+}
+
+func toJSONPosition(pos goimportmaps.Position) jsonPosition {
+	return jsonPosition{
+		File:   filepath.ToSlash(pos.File),
+		Line:   pos.Line,
+		Column: pos.Column,
+	}
+}
+
+func jsonSuppressions(suppressions []goimportmaps.Suppression) []jsonSuppression {
+	var result []jsonSuppression
+	for _, s := range suppressions {
+		result = append(result, jsonSuppression{
+			RuleID:   s.RuleID,
+			Reason:   s.Reason,
+			Position: toJSONPosition(s.Position),
+			Comment:  toJSONPosition(s.Comment),
+		})
+	}
+	return result
+}
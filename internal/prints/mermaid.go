@@ -4,22 +4,33 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/mickamy/goimportmaps"
 	"github.com/mickamy/goimportmaps/internal/config"
 	"github.com/mickamy/goimportmaps/internal/module"
 )
 
-func Mermaid(w io.Writer, graph goimportmaps.Graph, modulePath string, violations []config.Violation) {
+func Mermaid(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string, modules []string, violations []config.Violation) {
 	_, _ = fmt.Fprintln(w, "```mermaid")
 	_, _ = fmt.Fprintln(w, "graph TD")
 
-	violationMap := make(map[string]map[string]bool)
-	for _, v := range violations {
-		if violationMap[v.Source] == nil {
-			violationMap[v.Source] = make(map[string]bool)
+	type edgeViolation struct {
+		positions []goimportmaps.Position
+		errors    bool
+	}
+	violationMap := make(map[string]map[string]*edgeViolation)
+	for _, v := range config.Active(violations) {
+		from, to := v.Edge()
+		if violationMap[from] == nil {
+			violationMap[from] = make(map[string]*edgeViolation)
+		}
+		if violationMap[from][to] == nil {
+			violationMap[from][to] = &edgeViolation{}
 		}
-		violationMap[v.Source][v.Import] = true
+		// every violation of the same edge shares its positions
+		violationMap[from][to].positions = v.Positions
+		violationMap[from][to].errors = violationMap[from][to].errors || v.Severity == config.SeverityError
 	}
 
 	keys := make([]string, 0, len(graph))
@@ -32,17 +43,48 @@ func Mermaid(w io.Writer, graph goimportmaps.Graph, modulePath string, violation
 		toList := graph[from]
 		sort.Strings(toList)
 		for _, to := range toList {
-			from = module.Shorten(from, modulePath)
-			to = module.Shorten(to, modulePath)
-			if violationMap[from][to] {
-				_, _ = fmt.Fprintf(w, "  %s --> %s %% ❌ Violation\n", from, to)
+			shortFrom := module.Shorten(from, modulePath)
+			shortTo := module.Shorten(to, modulePath)
+			arrow := mermaidArrow(edges, from, to)
+			if violation, ok := violationMap[from][to]; ok {
+				label := "❌ Violation"
+				if !violation.errors {
+					label = "⚠️ Warning"
+				}
+				_, _ = fmt.Fprintf(w, "  %s %s %s %%%% %s%s\n", shortFrom, arrow, shortTo, label, formatPositions(violation.positions))
 			} else {
-				_, _ = fmt.Fprintf(w, "  %s --> %s\n", from, to)
+				_, _ = fmt.Fprintf(w, "  %s %s %s\n", shortFrom, arrow, shortTo)
 			}
 		}
 	}
 
-	_, _ = fmt.Fprintln(w, "```")
+	writeMermaidModules(w, graph, modulePath, modules)
 
 	_, _ = fmt.Fprintln(w, "```")
 }
+
+// formatPositions returns the positions as ` (a.go:1:2, b.go:3:4)`, or an empty string if there are none.
+func formatPositions(positions []goimportmaps.Position) string {
+	if len(positions) == 0 {
+		return ""
+	}
+
+	list := make([]string, 0, len(positions))
+	for _, pos := range positions {
+		list = append(list, pos.String())
+	}
+	return " (" + strings.Join(list, ", ") + ")"
+}
+
+// mermaidArrow returns a dotted arrow for imports that only appear in test files,
+// labeled with the weight of collapsed edges.
+func mermaidArrow(edges goimportmaps.Edges, from, to string) string {
+	arrow := "-->"
+	if edges.IsTest(from, to) {
+		arrow = "-.->"
+	}
+	if edge := edges.Get(from, to); edge != nil && edge.Weight > 0 {
+		arrow += fmt.Sprintf("|%d|", edge.Weight)
+	}
+	return arrow
+}
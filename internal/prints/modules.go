@@ -0,0 +1,81 @@
+package prints
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/module"
+)
+
+type moduleGroup struct {
+	Name     string
+	Packages []string
+}
+
+// groupByModule returns the packages of the graph grouped by the module they belong to,
+// or nil if there are fewer than two modules to tell apart.
+func groupByModule(graph goimportmaps.Graph, modulePath string, modules []string) []moduleGroup {
+	if len(modules) < 2 {
+		return nil
+	}
+
+	packages := make(map[string]map[string]bool)
+	add := func(pkg string) {
+		m := module.Of(pkg, modules)
+		if m == "" {
+			return
+		}
+		if packages[m] == nil {
+			packages[m] = make(map[string]bool)
+		}
+		packages[m][pkg] = true
+	}
+	for from, toList := range graph {
+		add(from)
+		for _, to := range toList {
+			add(to)
+		}
+	}
+
+	var groups []moduleGroup
+	for _, m := range modules {
+		if len(packages[m]) == 0 {
+			continue
+		}
+		group := moduleGroup{Name: module.Shorten(m, modulePath)}
+		if group.Name == "" {
+			group.Name = m
+		}
+		for pkg := range packages[m] {
+			group.Packages = append(group.Packages, module.Shorten(pkg, modulePath))
+		}
+		sort.Strings(group.Packages)
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// writeMermaidModules writes one subgraph per module.
+func writeMermaidModules(w io.Writer, graph goimportmaps.Graph, modulePath string, modules []string) {
+	for i, group := range groupByModule(graph, modulePath, modules) {
+		_, _ = fmt.Fprintf(w, "  subgraph module%d [%s]\n", i, group.Name)
+		for _, pkg := range group.Packages {
+			_, _ = fmt.Fprintf(w, "    %s\n", pkg)
+		}
+		_, _ = fmt.Fprintln(w, "  end")
+	}
+}
+
+// writeGraphvizModules writes one cluster per module.
+func writeGraphvizModules(w io.Writer, graph goimportmaps.Graph, modulePath string, modules []string) {
+	for i, group := range groupByModule(graph, modulePath, modules) {
+		_, _ = fmt.Fprintf(w, "  subgraph \"cluster_%d\" {\n", i)
+		_, _ = fmt.Fprintf(w, "    label=%q;\n", group.Name)
+		for _, pkg := range group.Packages {
+			_, _ = fmt.Fprintf(w, "    %q;\n", pkg)
+		}
+		_, _ = fmt.Fprintln(w, "  }")
+	}
+}
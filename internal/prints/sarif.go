@@ -0,0 +1,177 @@
+package prints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/config"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// SARIF writes the violations as a SARIF 2.1.0 log, so they can be ingested by code scanning tools.
+// Each violation becomes one result per import spec that caused it.
+func SARIF(w io.Writer, violations []config.Violation) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "goimportmaps",
+				InformationURI: "https://github.com/mickamy/goimportmaps",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range violations {
+		if !seen[v.RuleID] {
+			seen[v.RuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               v.RuleID,
+				ShortDescription: sarifMessage{Text: sarifRuleDescription(v)},
+			})
+		}
+
+		for _, pos := range v.Positions {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    v.RuleID,
+				Level:     sarifLevel(v.Severity),
+				Message:   sarifMessage{Text: v.Message},
+				Locations: []sarifLocation{sarifLocationOf(pos)},
+			})
+		}
+		// violations without a known position are reported once, without location
+		if len(v.Positions) == 0 && len(v.Suppressions) == 0 {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:  v.RuleID,
+				Level:   sarifLevel(v.Severity),
+				Message: sarifMessage{Text: v.Message},
+			})
+		}
+
+		// suppressed import specs are reported as results of their own, flagged as suppressed in source
+		for _, s := range v.Suppressions {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:       v.RuleID,
+				Level:        sarifLevel(v.Severity),
+				Message:      sarifMessage{Text: v.Message},
+				Locations:    []sarifLocation{sarifLocationOf(s.Position)},
+				Suppressions: []sarifSuppression{{Kind: "inSource", Justification: s.Reason}},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}); err != nil {
+		return fmt.Errorf("failed to encode sarif: %w", err)
+	}
+
+	return nil
+}
+
+func sarifLocationOf(pos goimportmaps.Position) sarifLocation {
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{
+				URI:       filepath.ToSlash(pos.File),
+				URIBaseID: "%SRCROOT%",
+			},
+			Region: sarifRegion{
+				StartLine:   pos.Line,
+				StartColumn: pos.Column,
+			},
+		},
+	}
+}
+
+func sarifLevel(severity config.Severity) string {
+	switch severity {
+	case config.SeverityWarning:
+		return "warning"
+	case config.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+func sarifRuleDescription(v config.Violation) string {
+	if v.Rule == "" {
+		return "import not matched by any allowed rule"
+	}
+	return v.Rule
+}
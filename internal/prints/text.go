@@ -7,35 +7,56 @@ import (
 	"strings"
 
 	"github.com/mickamy/goimportmaps"
+	"github.com/mickamy/goimportmaps/internal/config"
 	"github.com/mickamy/goimportmaps/internal/metrics"
 	"github.com/mickamy/goimportmaps/internal/module"
 )
 
-func Text(w io.Writer, graph goimportmaps.Graph, modulePath string) {
-	for from, toList := range graph {
+// Text writes one line per import, sorted, so that outputs can be compared.
+func Text(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string) {
+	keys := make([]string, 0, len(graph))
+	for from := range graph {
+		keys = append(keys, from)
+	}
+	sort.Strings(keys)
+
+	for _, from := range keys {
+		toList := append([]string(nil), graph[from]...)
+		sort.Strings(toList)
 		for _, to := range toList {
-			from = module.Shorten(from, modulePath)
-			to = module.Shorten(to, modulePath)
-			_, _ = fmt.Fprintf(w, "  %s --> %s\n", from, to)
+			_, _ = fmt.Fprintf(w, "  %s --> %s%s\n", module.Shorten(from, modulePath), module.Shorten(to, modulePath), textSuffix(edges, from, to))
 		}
 	}
 }
 
-func TextWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, analysis *metrics.CouplingAnalysis, maxEfferent, maxAfferent int, maxInstability float64) {
+// textSuffix returns the annotation appended to an edge in text output.
+func textSuffix(edges goimportmaps.Edges, from, to string) string {
+	var suffix string
+	edge := edges.Get(from, to)
+	if edge == nil {
+		return suffix
+	}
+	if edge.Test {
+		suffix += " (test)"
+	}
+	if len(edge.Builds) > 0 {
+		suffix += " [" + strings.Join(edge.Builds, ", ") + "]"
+	}
+	if edge.Weight > 0 {
+		suffix += fmt.Sprintf(" (weight %d)", edge.Weight)
+	}
+	return suffix
+}
+
+func TextWithMetrics(w io.Writer, graph goimportmaps.Graph, edges goimportmaps.Edges, modulePath string, analysis *metrics.CouplingAnalysis, metricsConfig config.Metrics) {
 	// Print dependency graph
 	fmt.Fprintf(w, "📊 Dependency Graph:\n")
-	for from, toList := range graph {
-		for _, to := range toList {
-			from = module.Shorten(from, modulePath)
-			to = module.Shorten(to, modulePath)
-			_, _ = fmt.Fprintf(w, "  %s --> %s\n", from, to)
-		}
-	}
+	Text(w, graph, edges, modulePath)
 
 	// Print coupling metrics
 	fmt.Fprintf(w, "\n📊 Coupling Metrics:\n\n")
-	fmt.Fprintf(w, "%-50s %4s %4s %6s %s\n", "Package", "Ca", "Ce", "I", "Status")
-	fmt.Fprintf(w, "%-50s %4s %4s %6s %s\n", strings.Repeat("-", 50), "----", "----", "------", "------")
+	fmt.Fprintf(w, "%-50s %4s %4s %6s %6s %6s %s\n", "Package", "Ca", "Ce", "I", "A", "D", "Status")
+	fmt.Fprintf(w, "%-50s %4s %4s %6s %6s %6s %s\n", strings.Repeat("-", 50), "----", "----", "------", "------", "------", "------")
 
 	// Sort packages for consistent output
 	var packages []string
@@ -47,37 +68,62 @@ func TextWithMetrics(w io.Writer, graph goimportmaps.Graph, modulePath string, a
 	for _, pkg := range packages {
 		metrics := analysis.Packages[pkg]
 		shortPkg := module.Shorten(pkg, modulePath)
-		status := getMetricsStatus(metrics, maxEfferent, maxAfferent, maxInstability)
+		status := getMetricsStatus(metrics, metricsConfig, pkg)
 		
-		fmt.Fprintf(w, "%-50s %4d %4d %6.2f %s\n", 
+		fmt.Fprintf(w, "%-50s %4d %4d %6.2f %6.2f %6.2f %s\n", 
 			shortPkg, 
 			metrics.AfferentCoupling, 
 			metrics.EfferentCoupling, 
 			metrics.Instability, 
+			metrics.Abstractness,
+			metrics.Distance,
 			status)
 	}
 
 	// Print violations
-	highCoupling := analysis.GetHighCouplingPackages(maxEfferent, maxAfferent, maxInstability)
+	highCoupling := highCouplingPackages(analysis, metricsConfig)
 	if len(highCoupling) > 0 {
 		fmt.Fprintf(w, "\n🚨 Coupling Violations:\n")
 		for _, pkg := range highCoupling {
 			shortPkg := module.Shorten(pkg.Package, modulePath)
-			reasons := getViolationReasons(pkg.Coupling, maxEfferent, maxAfferent, maxInstability)
+			thresholds, _ := metricsConfig.Thresholds(pkg.Package)
+			reasons := getViolationReasons(pkg.Coupling, thresholds.MaxEfferent, thresholds.MaxAfferent, thresholds.MaxInstability)
 			fmt.Fprintf(w, "- %s: %s\n", shortPkg, reasons)
 		}
 	}
 }
 
-func getMetricsStatus(metrics metrics.CouplingMetrics, maxEfferent, maxAfferent int, maxInstability float64) string {
-	if metrics.EfferentCoupling > maxEfferent || 
-	   metrics.AfferentCoupling > maxAfferent || 
-	   metrics.Instability > maxInstability {
+func getMetricsStatus(metrics metrics.CouplingMetrics, metricsConfig config.Metrics, pkg string) string {
+	thresholds, ok := metricsConfig.Thresholds(pkg)
+	if !ok {
+		return "➖"
+	}
+	if exceedsThresholds(metrics, thresholds) {
 		return "🚨"
 	}
 	return "✅"
 }
 
+// exceedsThresholds reports whether any of the metrics exceeds its max_* threshold.
+func exceedsThresholds(m metrics.CouplingMetrics, thresholds config.CouplingThresholds) bool {
+	return m.EfferentCoupling > thresholds.MaxEfferent ||
+		m.AfferentCoupling > thresholds.MaxAfferent ||
+		m.Instability > thresholds.MaxInstability
+}
+
+// highCouplingPackages returns the packages exceeding their thresholds, sorted.
+func highCouplingPackages(analysis *metrics.CouplingAnalysis, metricsConfig config.Metrics) []metrics.PackageMetrics {
+	var highCoupling []metrics.PackageMetrics
+	for pkg, m := range analysis.Packages {
+		thresholds, ok := metricsConfig.Thresholds(pkg)
+		if ok && exceedsThresholds(m, thresholds) {
+			highCoupling = append(highCoupling, metrics.PackageMetrics{Package: pkg, Coupling: m})
+		}
+	}
+	sort.Slice(highCoupling, func(i, j int) bool { return highCoupling[i].Package < highCoupling[j].Package })
+	return highCoupling
+}
+
 func getViolationReasons(metrics metrics.CouplingMetrics, maxEfferent, maxAfferent int, maxInstability float64) string {
 	var reasons []string
 	
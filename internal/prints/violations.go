@@ -0,0 +1,157 @@
+package prints
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mickamy/goimportmaps/internal/baseline"
+	"github.com/mickamy/goimportmaps/internal/config"
+)
+
+// Violations writes a summary line followed by one line per offending import spec,
+// prefixed with its position (e.g. `internal/handler/user.go:6:2: internal/handler imports ...`).
+// Violations without a known position are written once, without prefix.
+// Suppressed violations are not written.
+func Violations(w io.Writer, violations []config.Violation) {
+	violations = config.Active(violations)
+	if len(violations) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%s %s\n\n", severityEmoji(maxSeverity(violations)), summary(violations, "violation"))
+
+	for _, violation := range violations {
+		for _, line := range violationLines(violation) {
+			_, _ = fmt.Fprintln(w, severityEmoji(violation.Severity), "Violation:", line)
+		}
+	}
+}
+
+// CouplingViolations writes a summary line followed by one line per package exceeding a coupling threshold,
+// and per import spec of a package less stable than its importer.
+func CouplingViolations(w io.Writer, violations []config.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\n📊 %s\n\n", summary(violations, "coupling violation"))
+
+	for _, violation := range violations {
+		for _, line := range violationLines(violation) {
+			_, _ = fmt.Fprintln(w, severityEmoji(violation.Severity), "Coupling:", line)
+		}
+	}
+}
+
+// summary returns the summary line of the violations, counting errors apart from warnings and infos,
+// e.g. `1 violation(s), 3 warning(s) found`.
+func summary(violations []config.Violation, noun string) string {
+	var errors, warnings, infos int
+	for _, v := range violations {
+		switch v.Severity {
+		case config.SeverityWarning:
+			warnings++
+		case config.SeverityInfo:
+			infos++
+		default:
+			errors++
+		}
+	}
+
+	var parts []string
+	if errors > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s(s)", errors, noun))
+	}
+	if warnings > 0 {
+		parts = append(parts, fmt.Sprintf("%d warning(s)", warnings))
+	}
+	if infos > 0 {
+		parts = append(parts, fmt.Sprintf("%d info(s)", infos))
+	}
+	return strings.Join(parts, ", ") + " found"
+}
+
+// maxSeverity returns the highest severity of the violations.
+func maxSeverity(violations []config.Violation) config.Severity {
+	severity := config.SeverityInfo
+	for _, v := range violations {
+		switch {
+		case v.Severity != config.SeverityWarning && v.Severity != config.SeverityInfo:
+			return config.SeverityError
+		case v.Severity == config.SeverityWarning:
+			severity = config.SeverityWarning
+		}
+	}
+	return severity
+}
+
+func severityEmoji(severity config.Severity) string {
+	switch severity {
+	case config.SeverityWarning:
+		return "⚠️"
+	case config.SeverityInfo:
+		return "ℹ️"
+	default:
+		return "🚨"
+	}
+}
+
+// severityLabel returns the prefix of the messages of the given severity, empty for errors.
+func severityLabel(severity config.Severity) string {
+	switch severity {
+	case config.SeverityWarning, config.SeverityInfo:
+		return string(severity) + ": "
+	default:
+		return ""
+	}
+}
+
+// violationLines returns the violation message, labeled with its severity unless an error,
+// prefixed with each of its positions.
+func violationLines(v config.Violation) []string {
+	message := severityLabel(v.Severity) + v.Message
+	if len(v.Positions) == 0 {
+		return []string{message}
+	}
+
+	lines := make([]string, 0, len(v.Positions))
+	for _, pos := range v.Positions {
+		lines = append(lines, fmt.Sprintf("%s: %s", pos, message))
+	}
+	return lines
+}
+
+// suppressedLines returns the violation message prefixed with each of its suppressed positions,
+// followed by the suppression comment and its reason.
+func suppressedLines(v config.Violation) []string {
+	lines := make([]string, 0, len(v.Suppressions))
+	for _, s := range v.Suppressions {
+		line := fmt.Sprintf("%s: %s (suppressed at %s", s.Position, v.Message, s.Comment)
+		if s.Reason != "" {
+			line += ": " + s.Reason
+		}
+		lines = append(lines, line+")")
+	}
+	return lines
+}
+
+// Warnings writes one line per warning, e.g. about rules that are likely to be wrong.
+func Warnings(w io.Writer, warnings []string) {
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintln(w, "⚠️ Warning:", warning)
+	}
+}
+
+// Fixed writes the baseline entries that no longer occur, so that the baseline can be pruned.
+func Fixed(w io.Writer, fixed []baseline.Entry) {
+	if len(fixed) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\n🧹 %d baseline violation(s) fixed, run `goimportmaps baseline write` to prune them\n\n", len(fixed))
+
+	for _, entry := range fixed {
+		_, _ = fmt.Fprintln(w, "🧹 Fixed:", entry.Message)
+	}
+}
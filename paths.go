@@ -0,0 +1,210 @@
+package goimportmaps
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Direction is the direction in which imports are followed from a package.
+type Direction string
+
+const (
+	// DirectionOut follows the imports of packages.
+	DirectionOut Direction = "out"
+	// DirectionIn follows the importers of packages.
+	DirectionIn   Direction = "in"
+	DirectionBoth Direction = "both"
+)
+
+func NewDirection(s string) (Direction, error) {
+	switch d := Direction(s); d {
+	case DirectionOut, DirectionIn, DirectionBoth:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid direction: %s", s)
+	}
+}
+
+// ShortestPath returns the shortest import path from one package to another, both included,
+// or nil if to is not reachable from from.
+func (g Graph) ShortestPath(from, to string) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		toList := append([]string(nil), g[pkg]...)
+		sort.Strings(toList)
+		for _, next := range toList {
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			prev[next] = pkg
+			if next == to {
+				return buildPath(prev, from, to)
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil
+}
+
+// Reachable returns the packages reachable from the given one, directly or transitively, sorted.
+// The package itself is never included, even if it is part of a cycle.
+func (g Graph) Reachable(from string) []string {
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		for _, next := range g[pkg] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+
+	reachable := make([]string, 0, len(seen)-1)
+	for pkg := range seen {
+		if pkg != from {
+			reachable = append(reachable, pkg)
+		}
+	}
+	sort.Strings(reachable)
+	return reachable
+}
+
+// AllPaths returns the import paths from one package to another without visiting a package twice, shortest first.
+// If limit is positive, only the limit shortest ones are returned.
+func (g Graph) AllPaths(from, to string, limit int) [][]string {
+	// dist is the length of the shortest path from each package to to; packages not reaching to are left out
+	dist := map[string]int{to: 0}
+	reversed := g.Reverse()
+	queue := []string{to}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, prev := range reversed[pkg] {
+			if _, seen := dist[prev]; !seen {
+				dist[prev] = dist[pkg] + 1
+				queue = append(queue, prev)
+			}
+		}
+	}
+	if _, ok := dist[from]; !ok || from == to {
+		return nil
+	}
+
+	var paths [][]string
+	visited := map[string]bool{from: true}
+	path := []string{from}
+
+	// visit appends the paths from pkg to to of exactly remaining imports, and returns false once the limit is reached
+	var visit func(pkg string, remaining int) bool
+	visit = func(pkg string, remaining int) bool {
+		toList := append([]string(nil), g[pkg]...)
+		sort.Strings(toList)
+		for _, next := range toList {
+			d, ok := dist[next]
+			if !ok || visited[next] || d > remaining-1 {
+				continue
+			}
+			if next == to {
+				if remaining == 1 {
+					paths = append(paths, append(append([]string(nil), path...), to))
+					if limit > 0 && len(paths) >= limit {
+						return false
+					}
+				}
+				continue
+			}
+			visited[next] = true
+			path = append(path, next)
+			more := visit(next, remaining-1)
+			path = path[:len(path)-1]
+			visited[next] = false
+			if !more {
+				return false
+			}
+		}
+		return true
+	}
+
+	// a simple path goes through every package reaching to at most once
+	for length := dist[from]; length < len(dist); length++ {
+		if !visit(from, length) {
+			break
+		}
+	}
+	return paths
+}
+
+// Reverse returns the graph with every import reversed, i.e. mapping packages to their importers.
+func (g Graph) Reverse() Graph {
+	reversed := make(Graph)
+	for from, toList := range g {
+		for _, to := range toList {
+			reversed[to] = append(reversed[to], from)
+		}
+	}
+	for pkg := range reversed {
+		sort.Strings(reversed[pkg])
+	}
+	return reversed
+}
+
+// Neighborhood returns the subgraph of the packages at most depth imports away from the given ones,
+// following imports, importers or both, with every import between those packages.
+// A non-positive depth has no limit.
+func (g Graph) Neighborhood(packages []string, depth int, direction Direction) Graph {
+	var graphs []Graph
+	if direction == DirectionOut || direction == DirectionBoth {
+		graphs = append(graphs, g)
+	}
+	if direction == DirectionIn || direction == DirectionBoth {
+		graphs = append(graphs, g.Reverse())
+	}
+
+	seen := make(map[string]bool)
+	for _, pkg := range packages {
+		seen[pkg] = true
+	}
+	frontier := packages
+	for hops := 0; len(frontier) > 0 && (depth <= 0 || hops < depth); hops++ {
+		var next []string
+		for _, pkg := range frontier {
+			for _, graph := range graphs {
+				for _, neighbor := range graph[pkg] {
+					if seen[neighbor] {
+						continue
+					}
+					seen[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return g.Filter(func(from, to string) bool { return seen[from] && seen[to] })
+}
+
+func buildPath(prev map[string]string, from, to string) []string {
+	var path []string
+	for pkg := to; pkg != from; pkg = prev[pkg] {
+		path = append(path, pkg)
+	}
+	path = append(path, from)
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}